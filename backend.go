@@ -2,10 +2,15 @@ package splunk
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/splunk/vault-plugin-splunk/clients/splunk"
 )
@@ -13,6 +18,15 @@ import (
 type backend struct {
 	*framework.Backend
 	conn *sync.Map
+
+	// configLock serializes writes of connection configurations, including root rotations
+	configLock sync.Mutex
+	// staticRoleLock serializes password rotations of static roles
+	staticRoleLock sync.Mutex
+	// confSecretLock serializes rotations of conf secrets
+	confSecretLock sync.Mutex
+	// tidyLock serializes tidy runs, which keep state per connection
+	tidyLock sync.Mutex
 }
 
 // Factory is the factory function to create a Splunk backend.
@@ -31,21 +45,41 @@ func newBackend() logical.Backend {
 		PathsSpecial: &logical.Paths{
 			SealWrapStorage: []string{
 				"config/",
+				staticRolesPrefix,
+				confSecretsPrefix,
+				framework.WALPrefix,
 			},
 		},
 		Paths: []*framework.Path{
 			b.pathConfigConnection(),
 			b.pathConnectionsList(),
+			b.pathConnectionStatus(),
 			b.pathResetConnection(),
 			b.pathRotateRoot(),
 			b.pathRolesList(),
 			b.pathRoles(),
 			b.pathCredsCreate(),
 			b.pathCredsCreateMulti(),
+			b.pathTokensCreate(),
+			b.pathStaticRolesList(),
+			b.pathStaticRoles(),
+			b.pathStaticCreds(),
+			b.pathRotateRole(),
+			b.pathTidy(),
+			b.pathHECRolesList(),
+			b.pathHECRoles(),
+			b.pathHECCreds(),
+			b.pathConfSecretsList(),
+			b.pathConfSecrets(),
+			b.pathConfCreds(),
+			b.pathRotateConfSecret(),
 		},
 		Secrets: []*framework.Secret{
 			b.pathSecretCreds(),
+			b.pathSecretTokens(),
+			b.pathSecretHECCreds(),
 		},
+		PeriodicFunc:      b.periodicFunc,
 		WALRollback:       b.walRollback,
 		WALRollbackMinAge: walRollbackMinAge,
 		BackendType:       logical.TypeLogical,
@@ -56,8 +90,10 @@ func newBackend() logical.Backend {
 
 func (b *backend) ensureConnection(ctx context.Context, config *splunkConfig) (*splunk.API, error) {
 	if conn, ok := b.conn.Load(config.ID); ok {
+		connectionCacheAccessed(config, true)
 		return conn.(*splunk.API), nil
 	}
+	connectionCacheAccessed(config, false)
 
 	// create and cache connection
 	conn, err := config.newConnection(ctx)
@@ -71,15 +107,177 @@ func (b *backend) ensureConnection(ctx context.Context, config *splunkConfig) (*
 	return conn, nil
 }
 
-// clearConnection closes the connection and removes it from the cache.
+// periodicFunc performs scheduled maintenance, like rotating passwords that are due.
+func (b *backend) periodicFunc(ctx context.Context, req *logical.Request) error {
+	// the connection cache is local to this node
+	b.pruneNodeConnections(time.Now())
+
+	if b.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary | consts.ReplicationPerformanceStandby) {
+		// storage is not writable here; the primary takes care of rotations
+		return nil
+	}
+
+	var errs []string
+	if err := b.rotateExpiredRoots(ctx, req.Storage); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := b.rotateExpiredSecrets(ctx, req.Storage, staticRoleSecrets); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := b.rotateExpiredSecrets(ctx, req.Storage, confSecretSecrets); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := b.tidyConnections(ctx, req.Storage); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// clearConnection closes the connection and removes it from the cache, together with all node
+// connections of the same configuration.
 func (b *backend) clearConnection(id string) error {
 	b.conn.Delete(id)
+	prefix := id + "/"
+	b.conn.Range(func(key, _ interface{}) bool {
+		if strings.HasPrefix(key.(string), prefix) {
+			b.conn.Delete(key)
+		}
+		return true
+	})
 	return nil
 }
 
+const (
+	// maxNodeConnections bounds the number of cached node connections
+	maxNodeConnections = 256
+	// nodeConnectionIdleTimeout is the time after which unused node connections are dropped
+	nodeConnectionIdleTimeout = 10 * time.Minute
+)
+
+// nodeConnection is a cached connection to a node of a multi-node deployment.
+type nodeConnection struct {
+	api *splunk.API
+	// lastUsed is accessed atomically, in UnixNano
+	lastUsed int64
+}
+
+func nodeConnectionKey(id, nodeFQDN string) string {
+	return id + "/" + strings.ToLower(nodeFQDN)
+}
+
+// ensureNodeConnection returns a connection to the node nodeFQDN, or to the configured URL if nodeFQDN is empty.
+// For connections with shc_captain set, the empty nodeFQDN denotes the current search head cluster captain instead.
+// Node connections are cached like the connection to the configured URL.
+func (b *backend) ensureNodeConnection(ctx context.Context, config *splunkConfig, nodeFQDN string) (*splunk.API, error) {
+	b.Logger().Debug("node connection", "nodeFQDN", nodeFQDN)
+	if nodeFQDN == "" {
+		if config.SHCCaptain {
+			captain, err := b.shcCaptain(ctx, config)
+			if err != nil {
+				return nil, err
+			}
+			return b.ensureDiscoveredNodeConnection(ctx, config, captain)
+		}
+		return b.ensureConnection(ctx, config)
+	}
+	if conn := b.cachedNodeConnection(config, nodeFQDN); conn != nil {
+		return conn, nil
+	}
+
+	node, err := b.resolveNode(ctx, config, nodeFQDN)
+	if err != nil {
+		return nil, err
+	}
+	return b.newNodeConnection(ctx, config, nodeFQDN, node)
+}
+
+// ensureDiscoveredNodeConnection is like ensureNodeConnection, for a node that has been discovered already.
+func (b *backend) ensureDiscoveredNodeConnection(ctx context.Context, config *splunkConfig, node *discoveredNode) (*splunk.API, error) {
+	if conn := b.cachedNodeConnection(config, node.Host); conn != nil {
+		return conn, nil
+	}
+	return b.newNodeConnection(ctx, config, node.Host, node)
+}
+
+func (b *backend) cachedNodeConnection(config *splunkConfig, nodeFQDN string) *splunk.API {
+	cached, ok := b.conn.Load(nodeConnectionKey(config.ID, nodeFQDN))
+	if !ok {
+		return nil
+	}
+	nc := cached.(*nodeConnection)
+	atomic.StoreInt64(&nc.lastUsed, time.Now().UnixNano())
+	return nc.api
+}
+
+func (b *backend) newNodeConnection(ctx context.Context, config *splunkConfig, nodeFQDN string, node *discoveredNode) (*splunk.API, error) {
+	// we connect to a node, not the cluster master
+	var err error
+	nodeConfig := *config
+	nodeConfig.FailoverURLs = nil
+	if node.static != nil && node.static.URL != "" {
+		nodeConfig.URL = node.static.URL
+	} else if nodeConfig.URL, err = config.nodeURL(node); err != nil {
+		return nil, err
+	}
+	if node.static != nil && node.static.Username != "" {
+		nodeConfig.Username = node.static.Username
+		nodeConfig.Password = node.static.Password
+		nodeConfig.Token = ""
+	}
+	conn, err := nodeConfig.newConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nc := &nodeConnection{api: conn, lastUsed: time.Now().UnixNano()}
+	if cached, loaded := b.conn.LoadOrStore(nodeConnectionKey(config.ID, nodeFQDN), nc); loaded {
+		// somebody else won the race
+		return cached.(*nodeConnection).api, nil
+	}
+	b.pruneNodeConnections(time.Now())
+	return conn, nil
+}
+
+// pruneNodeConnections drops node connections that have been idle for too long.  If there are still
+// more than maxNodeConnections, the least recently used ones are dropped as well.
+func (b *backend) pruneNodeConnections(now time.Time) {
+	type entry struct {
+		key      interface{}
+		lastUsed int64
+	}
+	var active []entry
+	idleSince := now.Add(-nodeConnectionIdleTimeout).UnixNano()
+	b.conn.Range(func(key, value interface{}) bool {
+		nc, ok := value.(*nodeConnection)
+		if !ok {
+			return true
+		}
+		lastUsed := atomic.LoadInt64(&nc.lastUsed)
+		if lastUsed < idleSince {
+			b.conn.Delete(key)
+		} else {
+			active = append(active, entry{key, lastUsed})
+		}
+		return true
+	})
+	if len(active) <= maxNodeConnections {
+		return
+	}
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].lastUsed < active[j].lastUsed
+	})
+	for _, e := range active[:len(active)-maxNodeConnections] {
+		b.conn.Delete(e.key)
+	}
+}
+
 const backendHelp = `
 The Splunk backend rotates admin credentials and dynamically generates new
-users with limited life-time.
+users with limited life-time.  It can also manage the passwords of existing
+Splunk users via static roles, secrets in Splunk configuration files via
+conf secrets, and issue HTTP Event Collector tokens via HEC roles.
 
 After mounting this backend, credentials for a Splunk admin role must
 be configured and connections and roles must be written using
@@ -3,10 +3,12 @@ package splunk
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	logicaltest "github.com/hashicorp/vault/helper/testhelpers/logical"
+	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/mitchellh/mapstructure"
 	"gotest.tools/v3/assert"
@@ -52,10 +54,12 @@ func TestBackend_RotateRoot(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// rotate the password of a separate admin user, so that other tests can still log in
+	username, password := testNewAdminUser(t)
 	logicaltest.Test(t, logicaltest.TestCase{
 		LogicalBackend: b,
 		Steps: []logicaltest.TestStep{
-			testAccStepConfig(t),
+			testAccStepConfigUser(t, "testconn", username, password),
 			testAccRotateRoot(t, "testconn"),
 			// and again, to check if we can still login
 			testAccRotateRoot(t, "testconn"),
@@ -63,6 +67,467 @@ func TestBackend_RotateRoot(t *testing.T) {
 	})
 }
 
+func TestBackend_RotateRootWAL(t *testing.T) {
+	b, err := testNewSplunkBackend(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	storage := &testFailingStorage{Storage: &logical.InmemStorage{}}
+	username, password := testNewAdminUser(t)
+
+	testHandleRequest(t, b, storage, logical.CreateOperation, "config/testconn", map[string]interface{}{
+		"url":           splunk.TestGlobalSplunkClient(t).Params().BaseURL,
+		"username":      username,
+		"password":      password,
+		"allowed_roles": "*",
+		"insecure_tls":  true,
+	})
+
+	// fail between updating the password in Splunk and storing it
+	storage.failPutPrefix = "config/"
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "rotate-root/testconn",
+		Storage:   storage,
+	})
+	assert.ErrorContains(t, err, "injected storage failure")
+	storage.failPutPrefix = ""
+
+	assert.Equal(t, testCountWAL(t, storage, walTypeRoot), 1)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.RollbackOperation,
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"immediate": true,
+		},
+	})
+	assert.NilError(t, err)
+
+	assert.Equal(t, testCountWAL(t, storage, walTypeRoot), 0)
+
+	// the new password was stored, and can be used
+	config, err := connectionConfigLoad(ctx, storage, "testconn")
+	assert.NilError(t, err)
+	assert.Assert(t, config.Password != password)
+	conn := splunk.NewTestSplunkClient(config.URL, username, config.Password)
+	_, _, err = conn.Introspection.ServerInfo(ctx)
+	assert.NilError(t, err)
+}
+
+func TestBackend_CredsWAL(t *testing.T) {
+	b, err := testNewSplunkBackend(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	storage := &testFailingStorage{Storage: &logical.InmemStorage{}}
+	params := splunk.TestGlobalSplunkClient(t).Params()
+
+	testHandleRequest(t, b, storage, logical.CreateOperation, "config/testconn", map[string]interface{}{
+		"url":           params.BaseURL,
+		"username":      params.ClientID,
+		"password":      params.ClientSecret,
+		"allowed_roles": "*",
+		"insecure_tls":  true,
+	})
+	testHandleRequest(t, b, storage, logical.CreateOperation, rolesPrefix+"test", map[string]interface{}{
+		"connection": "testconn",
+		"roles":      "user",
+	})
+
+	// fail after the user was created, but before the credentials are returned
+	storage.failDeletePrefix = framework.WALPrefix
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "creds/test",
+		Storage:   storage,
+	})
+	assert.ErrorContains(t, err, "injected storage failure")
+	storage.failDeletePrefix = ""
+	assert.Equal(t, testCountWAL(t, storage, walTypeUser), 1)
+	walIDs, err := framework.ListWAL(ctx, storage)
+	assert.NilError(t, err)
+	var username string
+	for _, walID := range walIDs {
+		entry, err := framework.GetWAL(ctx, storage, walID)
+		assert.NilError(t, err)
+		if entry.Kind == walTypeUser {
+			var walEntry walUser
+			assert.NilError(t, mapstructure.Decode(entry.Data, &walEntry))
+			username = walEntry.Username
+		}
+	}
+	assert.Assert(t, username != "")
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.RollbackOperation,
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"immediate": true,
+		},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, testCountWAL(t, storage, walTypeUser), 0)
+
+	// the orphaned user was deleted
+	users, _, err := splunk.TestGlobalSplunkClient(t).AccessControl.Authentication.Users.Users(ctx, splunk.UserEntryFilterDefault)
+	assert.NilError(t, err)
+	for _, user := range users {
+		assert.Assert(t, user.Name != username, "found orphaned user %q", username)
+	}
+}
+
+func TestBackend_Tidy(t *testing.T) {
+	b, err := testNewSplunkBackend(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := &logical.InmemStorage{}
+	params := splunk.TestGlobalSplunkClient(t).Params()
+
+	testHandleRequest(t, b, storage, logical.CreateOperation, "config/testconn", map[string]interface{}{
+		"url":           params.BaseURL,
+		"username":      params.ClientID,
+		"password":      params.ClientSecret,
+		"allowed_roles": "*",
+		"is_standalone": true,
+		"insecure_tls":  true,
+	})
+	testHandleRequest(t, b, storage, logical.CreateOperation, rolesPrefix+"test", map[string]interface{}{
+		"connection":  "testconn",
+		"roles":       "user",
+		"user_prefix": "tidytest",
+	})
+	resp := testHandleRequest(t, b, storage, logical.ReadOperation, "creds/test", nil)
+	leased := resp.Data["username"].(string)
+
+	users := splunk.TestGlobalSplunkClient(t).AccessControl.Authentication.Users
+	orphan := "tidytest_orphan"
+	_, _, err = users.Create(context.Background(), &splunk.CreateUserOptions{
+		Name:     orphan,
+		Password: "orphan1234",
+		Roles:    []string{"user"},
+	})
+	assert.NilError(t, err)
+	t.Cleanup(func() {
+		// nolint:errcheck
+		users.Delete(context.Background(), orphan)
+		// nolint:errcheck
+		users.Delete(context.Background(), leased)
+	})
+
+	// a dry run does not start the safety buffer
+	resp = testHandleRequest(t, b, storage, logical.UpdateOperation, "tidy/testconn", map[string]interface{}{
+		"safety_buffer": 0,
+		"dry_run":       true,
+	})
+	assert.DeepEqual(t, resp.Data["pending"], []string{orphan})
+	state, err := tidyStateLoad(context.Background(), storage, "testconn")
+	assert.NilError(t, err)
+	assert.Equal(t, len(state.Candidates), 0)
+
+	// orphans are only deleted after the safety buffer
+	resp = testHandleRequest(t, b, storage, logical.UpdateOperation, "tidy/testconn", nil)
+	assert.DeepEqual(t, resp.Data["deleted"], []string(nil))
+	assert.DeepEqual(t, resp.Data["pending"], []string{orphan})
+
+	// users unknown to the user index might belong to leases issued before it existed,
+	// and are kept for the max TTL even without safety buffer
+	resp = testHandleRequest(t, b, storage, logical.UpdateOperation, "tidy/testconn", map[string]interface{}{
+		"safety_buffer": 0,
+	})
+	assert.DeepEqual(t, resp.Data["deleted"], []string(nil))
+	assert.DeepEqual(t, resp.Data["pending"], []string{orphan})
+	assert.Assert(t, testUserExists(t, orphan))
+
+	state, err = tidyStateLoad(context.Background(), storage, "testconn")
+	assert.NilError(t, err)
+	candidate := state.Candidates[orphan]
+	candidate.FirstSeen = candidate.FirstSeen.Add(-b.(*backend).System().MaxLeaseTTL() - time.Minute)
+	state.Candidates[orphan] = candidate
+	assert.NilError(t, state.store(context.Background(), storage, "testconn"))
+
+	resp = testHandleRequest(t, b, storage, logical.UpdateOperation, "tidy/testconn", map[string]interface{}{
+		"safety_buffer": 0,
+		"dry_run":       true,
+	})
+	assert.DeepEqual(t, resp.Data["deleted"], []string{orphan})
+	assert.Assert(t, testUserExists(t, orphan))
+
+	resp = testHandleRequest(t, b, storage, logical.UpdateOperation, "tidy/testconn", map[string]interface{}{
+		"safety_buffer": 0,
+	})
+	assert.DeepEqual(t, resp.Data["deleted"], []string{orphan})
+	assert.Assert(t, !testUserExists(t, orphan))
+	assert.Assert(t, testUserExists(t, leased))
+}
+
+func TestBackend_Tokens(t *testing.T) {
+	b, err := testNewSplunkBackend(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := &logical.InmemStorage{}
+	params := splunk.TestGlobalSplunkClient(t).Params()
+	splunk.TestEnableTokenAuth(t, splunk.TestGlobalSplunkClient(t))
+
+	testHandleRequest(t, b, storage, logical.CreateOperation, "config/testconn", map[string]interface{}{
+		"url":           params.BaseURL,
+		"username":      params.ClientID,
+		"password":      params.ClientSecret,
+		"allowed_roles": "*",
+		"insecure_tls":  true,
+	})
+	testHandleRequest(t, b, storage, logical.CreateOperation, rolesPrefix+"tokens", map[string]interface{}{
+		"connection":     "testconn",
+		"token_user":     params.ClientID,
+		"token_audience": "test",
+		"default_ttl":    "1h",
+	})
+
+	resp := testHandleRequest(t, b, storage, logical.ReadOperation, "tokens/tokens", nil)
+	assert.Assert(t, resp.Data["token"].(string) != "")
+	assert.Equal(t, resp.Secret.TTL, time.Hour)
+	tokenID := resp.Data["token_id"].(string)
+	assert.Assert(t, testTokenExists(t, tokenID))
+
+	_, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.RevokeOperation,
+		Storage:   storage,
+		Secret:    resp.Secret,
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, !testTokenExists(t, tokenID))
+}
+
+func TestBackend_DynamicRole(t *testing.T) {
+	b, err := testNewSplunkBackend(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := &logical.InmemStorage{}
+	params := splunk.TestGlobalSplunkClient(t).Params()
+	roles := splunk.TestGlobalSplunkClient(t).AccessControl.Authorization.Roles
+
+	testHandleRequest(t, b, storage, logical.CreateOperation, "config/testconn", map[string]interface{}{
+		"url":           params.BaseURL,
+		"username":      params.ClientID,
+		"password":      params.ClientSecret,
+		"allowed_roles": "*",
+		"insecure_tls":  true,
+	})
+	testHandleRequest(t, b, storage, logical.CreateOperation, rolesPrefix+"dynamic", map[string]interface{}{
+		"connection":             "testconn",
+		"dynamic_role":           true,
+		"capabilities":           "search",
+		"imported_roles":         "user",
+		"search_indexes_allowed": "main",
+		"search_filter":          "sourcetype=test",
+	})
+
+	resp := testHandleRequest(t, b, storage, logical.ReadOperation, "creds/dynamic", nil)
+	splunkRole := dynamicRoleName(resp.Data["username"].(string))
+	assert.DeepEqual(t, resp.Data["roles"], []string{splunkRole})
+	role, _, err := roles.Role(context.Background(), splunkRole)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, role.Content.Capabilities, []string{"search"})
+	assert.DeepEqual(t, role.Content.ImportedRoles, []string{"user"})
+	assert.DeepEqual(t, role.Content.SrchIndexesAllowed, []string{"main"})
+	assert.Equal(t, role.Content.SrchFilter, "sourcetype=test")
+
+	_, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.RevokeOperation,
+		Storage:   storage,
+		Secret:    resp.Secret,
+	})
+	assert.NilError(t, err)
+	_, _, err = roles.Role(context.Background(), splunkRole)
+	assert.ErrorContains(t, err, "")
+}
+
+func TestBackend_HECCreds(t *testing.T) {
+	b, err := testNewSplunkBackend(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := &logical.InmemStorage{}
+	params := splunk.TestGlobalSplunkClient(t).Params()
+	splunk.TestEnableHEC(t, splunk.TestGlobalSplunkClient(t))
+
+	testHandleRequest(t, b, storage, logical.CreateOperation, "config/testconn", map[string]interface{}{
+		"url":           params.BaseURL,
+		"username":      params.ClientID,
+		"password":      params.ClientSecret,
+		"allowed_roles": "*",
+		"insecure_tls":  true,
+	})
+	testHandleRequest(t, b, storage, logical.CreateOperation, hecRolesPrefix+"hec", map[string]interface{}{
+		"connection": "testconn",
+		"index":      "main",
+		"sourcetype": "test",
+	})
+
+	resp := testHandleRequest(t, b, storage, logical.ReadOperation, "hec-creds/hec", nil)
+	assert.Assert(t, resp.Data["token"].(string) != "")
+	name := resp.Data["name"].(string)
+	assert.Assert(t, testHECInputExists(t, name))
+
+	_, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.RevokeOperation,
+		Storage:   storage,
+		Secret:    resp.Secret,
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, !testHECInputExists(t, name))
+}
+
+func TestBackend_ConfSecret(t *testing.T) {
+	b, err := testNewSplunkBackend(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := &logical.InmemStorage{}
+	conn := splunk.TestGlobalSplunkClient(t)
+	params := conn.Params()
+
+	testHandleRequest(t, b, storage, logical.CreateOperation, "config/testconn", map[string]interface{}{
+		"url":           params.BaseURL,
+		"username":      params.ClientID,
+		"password":      params.ClientSecret,
+		"allowed_roles": "*",
+		"insecure_tls":  true,
+	})
+	testHandleRequest(t, b, storage, logical.CreateOperation, confSecretsPrefix+"symmkey", map[string]interface{}{
+		"connection":      "testconn",
+		"file":            "server",
+		"stanza":          "general",
+		"key":             "pass4SymmKey",
+		"rotation_period": 3600,
+	})
+
+	resp := testHandleRequest(t, b, storage, logical.ReadOperation, "conf-creds/symmkey", nil)
+	value := resp.Data["value"].(string)
+	assert.Assert(t, value != "")
+	current, _, err := conn.Properties.GetKey(context.Background(), "server", "general", "pass4SymmKey")
+	assert.NilError(t, err)
+	assert.Equal(t, *current, value)
+
+	testHandleRequest(t, b, storage, logical.UpdateOperation, "rotate-conf-secret/symmkey", nil)
+	resp = testHandleRequest(t, b, storage, logical.ReadOperation, "conf-creds/symmkey", nil)
+	assert.Assert(t, resp.Data["value"].(string) != value)
+	current, _, err = conn.Properties.GetKey(context.Background(), "server", "general", "pass4SymmKey")
+	assert.NilError(t, err)
+	assert.Equal(t, *current, resp.Data["value"].(string))
+}
+
+func TestBackend_ConnectionStatus(t *testing.T) {
+	b, err := testNewSplunkBackend(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := &logical.InmemStorage{}
+	conn := splunk.TestGlobalSplunkClient(t)
+	params := conn.Params()
+
+	testHandleRequest(t, b, storage, logical.CreateOperation, "config/testconn", map[string]interface{}{
+		"url":           params.BaseURL,
+		"username":      params.ClientID,
+		"password":      params.ClientSecret,
+		"allowed_roles": "*",
+		"insecure_tls":  true,
+		"is_standalone": true,
+	})
+	resp := testHandleRequest(t, b, storage, logical.ReadOperation, "config/testconn/status", nil)
+	assert.Equal(t, resp.Data["reachable"], true)
+	assert.Equal(t, resp.Data["authenticated"], true)
+	assert.Assert(t, resp.Data["version"].(string) != "")
+	assert.Assert(t, resp.Data["guid"].(string) != "")
+
+	testHandleRequest(t, b, storage, logical.UpdateOperation, "config/testconn", map[string]interface{}{
+		"password": "wrong",
+		"verify":   false,
+	})
+	resp = testHandleRequest(t, b, storage, logical.ReadOperation, "config/testconn/status", nil)
+	assert.Equal(t, resp.Data["reachable"], true)
+	assert.Equal(t, resp.Data["authenticated"], false)
+	assert.Assert(t, resp.Data["error"].(string) != "")
+}
+
+func TestBackend_StaticRole(t *testing.T) {
+	b, err := testNewSplunkBackend(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := splunk.TestGlobalSplunkClient(t).AccessControl.Authentication.Users
+	user, _, err := users.Create(context.Background(), &splunk.CreateUserOptions{
+		Name:     "static-" + t.Name(),
+		Password: "initial1234",
+		Roles:    []string{"user"},
+	})
+	assert.NilError(t, err)
+	// nolint:errcheck
+	defer users.Delete(context.Background(), user.Name)
+
+	var passwords []string
+	logicaltest.Test(t, logicaltest.TestCase{
+		LogicalBackend: b,
+		Steps: []logicaltest.TestStep{
+			testAccStepConfig(t),
+			testAccStepStaticRole(t, "static", user.Name),
+			testAccStepStaticCredsRead(t, "static", &passwords),
+			testAccStepRotateRole(t, "static"),
+			testAccStepStaticCredsRead(t, "static", &passwords),
+		},
+	})
+	assert.Equal(t, len(passwords), 2)
+	assert.Assert(t, passwords[0] != passwords[1])
+}
+
+func TestBackend_NodeConnectionCache(t *testing.T) {
+	b := newBackend().(*backend)
+	ctx := context.Background()
+	config := &splunkConfig{ID: "id1", URL: "https://localhost:8089"}
+
+	conn1, err := b.ensureNodeConnection(ctx, config, "idx1.example.com")
+	assert.NilError(t, err)
+	conn2, err := b.ensureNodeConnection(ctx, config, "IDX1.example.com")
+	assert.NilError(t, err)
+	assert.Equal(t, conn1, conn2)
+	assert.Equal(t, conn1.Params().BaseURL, "https://idx1.example.com:8089")
+
+	// idle connections are dropped
+	b.pruneNodeConnections(time.Now().Add(2 * nodeConnectionIdleTimeout))
+	conn2, err = b.ensureNodeConnection(ctx, config, "idx1.example.com")
+	assert.NilError(t, err)
+	assert.Assert(t, conn1 != conn2)
+
+	// least recently used connections are dropped
+	for i := 0; i < maxNodeConnections+10; i++ {
+		_, err := b.ensureNodeConnection(ctx, config, fmt.Sprintf("idx%d.example.com", i+2))
+		assert.NilError(t, err)
+	}
+	count := 0
+	b.conn.Range(func(_, _ interface{}) bool {
+		count++
+		return true
+	})
+	assert.Equal(t, count, maxNodeConnections)
+
+	// node connections are cleared together with the connection
+	_, err = b.ensureConnection(ctx, config)
+	assert.NilError(t, err)
+	assert.NilError(t, b.clearConnection(config.ID))
+	count = 0
+	b.conn.Range(func(_, _ interface{}) bool {
+		count++
+		return true
+	})
+	assert.Equal(t, count, 0)
+}
+
 func TestBackend_ConnectionCRUD(t *testing.T) {
 	b, err := testNewSplunkBackend(t)
 	if err != nil {
@@ -72,12 +537,18 @@ func TestBackend_ConnectionCRUD(t *testing.T) {
 	connConfig := splunkConfig{
 		Username:       splunk.TestGlobalSplunkClient(t).Params().Config.ClientID,
 		URL:            splunk.TestGlobalSplunkClient(t).Params().BaseURL,
+		FailoverURLs:   []string{},
 		AllowedRoles:   []string{"*"},
 		Verify:         true,
 		InsecureTLS:    true,
 		CAChain:        []string{},
 		RootCA:         []string{},
 		ConnectTimeout: time.Duration(30) * time.Second,
+
+		RetryMaxAttempts: defaultRetryMaxAttempts,
+		RetryBackoff:     time.Second,
+		RetryMaxBackoff:  30 * time.Second,
+		RetryStatusCodes: []int{},
 	}
 
 	logicaltest.Test(t, logicaltest.TestCase{
@@ -86,6 +557,7 @@ func TestBackend_ConnectionCRUD(t *testing.T) {
 			testAccStepConfig(t),
 			testAccStepConnectionRead(t, "testconn", connConfig),
 			testAccStepConnectionDelete(t, "testconn"),
+			testAccStepConfigBadPassword(t, "badconn"),
 		},
 	})
 }
@@ -125,6 +597,15 @@ func TestBackend_RoleCRUD(t *testing.T) {
 		},
 	})
 
+	shortPasswordConfig := testRoleConfig
+	shortPasswordConfig.PasswordSpec = &PasswordSpec{Length: 4}
+	logicaltest.Test(t, logicaltest.TestCase{
+		LogicalBackend: b,
+		Steps: []logicaltest.TestStep{
+			testRolePasswordSpec(t, "test", shortPasswordConfig, "invalid password settings: password length must be at least 8"),
+		},
+	})
+
 	userIDSchemeConfig := testRoleConfig
 	userIDSchemeConfig.UserIDScheme = "-invalid-"
 	logicaltest.Test(t, logicaltest.TestCase{
@@ -152,6 +633,42 @@ func testAccStepConfig(t *testing.T) logicaltest.TestStep {
 	}
 }
 
+func testAccStepConfigUser(t *testing.T, conn, username, password string) logicaltest.TestStep {
+	return logicaltest.TestStep{
+		Operation: logical.UpdateOperation,
+		Path:      "config/" + conn,
+		Data: map[string]interface{}{
+			"url":           splunk.TestGlobalSplunkClient(t).Params().BaseURL,
+			"username":      username,
+			"password":      password,
+			"allowed_roles": "*",
+			"insecure_tls":  true,
+		},
+	}
+}
+
+func testAccStepConfigBadPassword(t *testing.T, conn string) logicaltest.TestStep {
+	return logicaltest.TestStep{
+		Operation: logical.UpdateOperation,
+		Path:      "config/" + conn,
+		Data: map[string]interface{}{
+			"url":           splunk.TestGlobalSplunkClient(t).Params().BaseURL,
+			"username":      splunk.TestGlobalSplunkClient(t).Params().Config.ClientID,
+			"password":      "-invalid-",
+			"allowed_roles": "*",
+			"insecure_tls":  true,
+		},
+		ErrorOk: true,
+		Check: func(resp *logical.Response) error {
+			if resp == nil {
+				return fmt.Errorf("response is nil")
+			}
+			assert.ErrorContains(t, resp.Error(), "error verifying connection: unable to log in")
+			return nil
+		},
+	}
+}
+
 func testAccStepConnectionRead(t *testing.T, conn string, config splunkConfig) logicaltest.TestStep {
 	return logicaltest.TestStep{
 		Operation: logical.ReadOperation,
@@ -252,6 +769,22 @@ func testUserIDScheme(t *testing.T, role, idScheme string, config roleConfig) lo
 	}
 }
 
+func testRolePasswordSpec(t *testing.T, role string, config roleConfig, expectedErr string) logicaltest.TestStep {
+	return logicaltest.TestStep{
+		Operation: logical.CreateOperation,
+		Path:      rolesPrefix + role,
+		Data:      config.toResponseData(),
+		ErrorOk:   true,
+		Check: func(resp *logical.Response) error {
+			if resp == nil {
+				return fmt.Errorf("response is nil")
+			}
+			assert.Error(t, resp.Error(), expectedErr)
+			return nil
+		},
+	}
+}
+
 func testAccStepCredsRead(t *testing.T, role string) logicaltest.TestStep {
 	return logicaltest.TestStep{
 		Operation: logical.ReadOperation,
@@ -270,7 +803,7 @@ func testAccStepCredsRead(t *testing.T, role string) logicaltest.TestStep {
 			}
 			// check that generated user can login
 			conn := splunk.NewTestSplunkClient(d.URL, d.Username, d.Password)
-			_, _, err := conn.Introspection.ServerInfo()
+			_, _, err := conn.Introspection.ServerInfo(context.Background())
 			assert.NilError(t, err)
 
 			// XXXX check that generated user is deleted if lease expires
@@ -294,6 +827,56 @@ func testAccStepCredsReadMultiBadConfig(t *testing.T, role string) logicaltest.T
 	}
 }
 
+// Static role
+func testAccStepStaticRole(t *testing.T, role, username string) logicaltest.TestStep {
+	return logicaltest.TestStep{
+		Operation: logical.UpdateOperation,
+		Path:      staticRolesPrefix + role,
+		Data: map[string]interface{}{
+			"connection":      "testconn",
+			"username":        username,
+			"rotation_period": "1h",
+		},
+	}
+}
+
+func testAccStepStaticCredsRead(t *testing.T, role string, passwords *[]string) logicaltest.TestStep {
+	return logicaltest.TestStep{
+		Operation: logical.ReadOperation,
+		Path:      "static-creds/" + role,
+		Check: func(resp *logical.Response) error {
+			if resp == nil {
+				return fmt.Errorf("response is nil")
+			}
+			var d struct {
+				Username string `mapstructure:"username"`
+				Password string `mapstructure:"password"`
+				URL      string `mapstructure:"url"`
+				TTL      int64  `mapstructure:"ttl"`
+			}
+			if err := mapstructure.Decode(resp.Data, &d); err != nil {
+				return err
+			}
+			assert.Assert(t, d.TTL > 0)
+
+			// check that the managed user can login
+			conn := splunk.NewTestSplunkClient(d.URL, d.Username, d.Password)
+			_, _, err := conn.Introspection.ServerInfo(context.Background())
+			assert.NilError(t, err)
+
+			*passwords = append(*passwords, d.Password)
+			return nil
+		},
+	}
+}
+
+func testAccStepRotateRole(t *testing.T, role string) logicaltest.TestStep {
+	return logicaltest.TestStep{
+		Operation: logical.UpdateOperation,
+		Path:      "rotate-role/" + role,
+	}
+}
+
 func testAccRotateRoot(t *testing.T, conn string) logicaltest.TestStep {
 	return logicaltest.TestStep{
 		Operation: logical.UpdateOperation,
@@ -338,6 +921,111 @@ func testAccStepRoleDelete(t *testing.T, role string) logicaltest.TestStep {
 }
 
 // Helpers
+
+// testFailingStorage fails writes or deletes of keys with a given prefix.
+type testFailingStorage struct {
+	logical.Storage
+	failPutPrefix    string
+	failDeletePrefix string
+}
+
+func testHandleRequest(t *testing.T, b logical.Backend, s logical.Storage, op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+	t.Helper()
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: op,
+		Path:      path,
+		Storage:   s,
+		Data:      data,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, resp.Error())
+	return resp
+}
+
+func testCountWAL(t *testing.T, s logical.Storage, kind string) int {
+	t.Helper()
+	ctx := context.Background()
+	walIDs, err := framework.ListWAL(ctx, s)
+	assert.NilError(t, err)
+	count := 0
+	for _, walID := range walIDs {
+		entry, err := framework.GetWAL(ctx, s, walID)
+		assert.NilError(t, err)
+		if entry != nil && entry.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *testFailingStorage) Put(ctx context.Context, entry *logical.StorageEntry) error {
+	if s.failPutPrefix != "" && strings.HasPrefix(entry.Key, s.failPutPrefix) {
+		return fmt.Errorf("injected storage failure")
+	}
+	return s.Storage.Put(ctx, entry)
+}
+
+func (s *testFailingStorage) Delete(ctx context.Context, key string) error {
+	if s.failDeletePrefix != "" && strings.HasPrefix(key, s.failDeletePrefix) {
+		return fmt.Errorf("injected storage failure")
+	}
+	return s.Storage.Delete(ctx, key)
+}
+
+func testNewAdminUser(t *testing.T) (username, password string) {
+	t.Helper()
+	users := splunk.TestGlobalSplunkClient(t).AccessControl.Authentication.Users
+	username = "admin-" + t.Name()
+	password = "test1234"
+	_, _, err := users.Create(context.Background(), &splunk.CreateUserOptions{
+		Name:     username,
+		Password: password,
+		Roles:    []string{"admin"},
+	})
+	assert.NilError(t, err)
+	t.Cleanup(func() {
+		// nolint:errcheck
+		users.Delete(context.Background(), username)
+	})
+	return username, password
+}
+
+func testUserExists(t *testing.T, username string) bool {
+	t.Helper()
+	users, _, err := splunk.TestGlobalSplunkClient(t).AccessControl.Authentication.Users.Users(context.Background(), splunk.UserEntryFilterPrefix(username))
+	assert.NilError(t, err)
+	for _, user := range users {
+		if user.Name == username {
+			return true
+		}
+	}
+	return false
+}
+
+func testTokenExists(t *testing.T, id string) bool {
+	t.Helper()
+	tokens, _, err := splunk.TestGlobalSplunkClient(t).AccessControl.Authorization.Tokens.Tokens(context.Background(), splunk.TokenEntryFilterDefault)
+	assert.NilError(t, err)
+	for _, token := range tokens {
+		if token.Name == id {
+			return true
+		}
+	}
+	return false
+}
+
+func testHECInputExists(t *testing.T, name string) bool {
+	t.Helper()
+	inputs, _, err := splunk.TestGlobalSplunkClient(t).HEC.Inputs(context.Background(), splunk.HECEntryFilterDefault)
+	assert.NilError(t, err)
+	for _, input := range inputs {
+		if input.Name == "http://"+name {
+			return true
+		}
+	}
+	return false
+}
+
 func testNewSplunkBackend(t *testing.T) (logical.Backend, error) {
 	t.Helper()
 	if splunk.TestGlobalSplunkClient(t) == nil {
@@ -4,11 +4,13 @@ package splunk
 type AccessControlService struct {
 	client         *Client
 	Authentication *AuthenticationService
+	Authorization  *AuthorizationService
 }
 
 func newAccessControlService(client *Client) *AccessControlService {
 	return &AccessControlService{
 		client:         client,
 		Authentication: newAuthenticationService(client.New()),
+		Authorization:  newAuthorizationService(client.New()),
 	}
 }
@@ -1,5 +1,7 @@
 package splunk
 
+import "context"
+
 // AuthenticationService encapsulates the Authentication portion of the Splunk API.
 type AuthenticationService struct {
 	client     *Client
@@ -28,14 +30,45 @@ type LoginResponse struct {
 }
 
 // Login returns a valid session key, or an error.
-func (s *AuthenticationService) Login(username, password string) (*LoginResponse, error) {
+func (s *AuthenticationService) Login(ctx context.Context, username, password string) (*LoginResponse, error) {
 	creds := userCredentials{username, password}
-	apiResp := &LoginResponse{}
-	apiErr := &APIError{}
+	for attempt := 1; ; attempt++ {
+		apiResp := &LoginResponse{}
+		apiErr := &APIError{}
+
+		httpResp, err := receive(ctx, s.authClient.New().BodyForm(&creds).Post("login"), apiResp, apiErr)
+		if err = relevantError(httpResp, err, apiErr); err != nil { // XXX check fatal
+			// logging in is safe to retry
+			if s.authClient.retry(ctx, attempt, &Response{HTTPResponse: httpResp}, err) {
+				continue
+			}
+			return nil, err
+		}
+		return apiResp, err
+	}
+}
+
+// ContextEntry is returned from CurrentContext() calls.
+type ContextEntry struct {
+	EntryMetadata
+	Name    string `json:"name"`
+	Content struct {
+		Capabilities []string `json:"capabilities"`
+		DefaultApp   string   `json:"defaultApp"`
+		Email        string   `json:"email"`
+		RealName     string   `json:"realname"`
+		Roles        []string `json:"roles"`
+		TZ           string   `json:"tz"`
+		Username     string   `json:"username"`
+	} `json:"content"`
+}
 
-	_, err := s.authClient.New().BodyForm(&creds).Post("login").Receive(apiResp, apiErr)
-	if err != nil || !apiErr.Empty() { // XXX check fatal
-		return nil, relevantError(err, apiErr)
+// CurrentContext returns information about the currently authenticated user, including its capabilities.
+func (s *AuthenticationService) CurrentContext(ctx context.Context) (*ContextEntry, *Response, error) {
+	entries := make([]ContextEntry, 0)
+	resp, err := Receive(ctx, s.client.New().Get("current-context"), &entries)
+	if err != nil || len(entries) == 0 {
+		return nil, resp, err
 	}
-	return apiResp, err
+	return &entries[0], resp, err
 }
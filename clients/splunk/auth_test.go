@@ -1,6 +1,7 @@
 package splunk
 
 import (
+	"context"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -15,7 +16,7 @@ func TestAuthenticationService_Login(t *testing.T) {
 	svc := TestGlobalSplunkClient(t).AccessControl.Authentication
 	username := testGlobalSplunkConn.Params().ClientID
 	password := testGlobalSplunkConn.Params().ClientSecret
-	resp, err := svc.Login(username, password)
+	resp, err := svc.Login(context.Background(), username, password)
 	assert.NilError(t, err)
 	assert.Assert(t, len(resp.SessionKey) > 0)
 	t.Logf("session key for %q: %v", username, resp.SessionKey)
@@ -23,6 +24,14 @@ func TestAuthenticationService_Login(t *testing.T) {
 
 func TestAuthenticationService_Login_Failed(t *testing.T) {
 	svc := TestGlobalSplunkClient(t).AccessControl.Authentication
-	_, err := svc.Login("", "")
+	_, err := svc.Login(context.Background(), "", "")
 	assert.Error(t, err, "WARN splunk: Login failed")
 }
+
+func TestAuthenticationService_CurrentContext(t *testing.T) {
+	svc := TestGlobalSplunkClient(t).AccessControl.Authentication
+	entry, _, err := svc.CurrentContext(context.Background())
+	assert.NilError(t, err)
+	assert.Equal(t, entry.Content.Username, testGlobalSplunkConn.Params().ClientID)
+	assert.Assert(t, len(entry.Content.Capabilities) > 0)
+}
@@ -0,0 +1,17 @@
+package splunk
+
+// AuthorizationService encapsulates the Authorization portion of the Splunk API.
+type AuthorizationService struct {
+	client *Client
+	Roles  *RoleService
+	Tokens *TokenService
+}
+
+func newAuthorizationService(client *Client) *AuthorizationService {
+	base := client.New().Path("authorization/")
+	return &AuthorizationService{
+		client: base,
+		Roles:  newRoleService(base.New()),
+		Tokens: newTokenService(base.New()),
+	}
+}
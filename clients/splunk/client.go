@@ -13,6 +13,7 @@ import (
 // The Client type wraps the underlying API transport.
 type Client struct {
 	*sling.Sling
+	retryPolicy *RetryPolicy
 }
 
 // APIParams provides the configuration for setting up a new API client with the NewClient() function.
@@ -21,6 +22,17 @@ type APIParams struct {
 	UserAgent string
 	TokenTTL  time.Duration
 
+	// Retry configures retries of failed API calls; nil disables retries
+	Retry *RetryPolicy
+
+	// FailoverURLs are used in order if BaseURL is unavailable
+	FailoverURLs []string
+	// FailoverRecovery is the time after which an unavailable URL is checked again; default: DefaultFailoverRecovery
+	FailoverRecovery time.Duration
+
+	// BearerToken is a Splunk authentication token, used instead of logging in with ClientID and ClientSecret
+	BearerToken string
+
 	// pass in an actual OAuth2 client, if supported by Splunk; if nil, use Splunk's basic auth/sessionkey token flow
 	AuthClient *http.Client
 	oauth2.Config
@@ -34,18 +46,29 @@ func (p *APIParams) defaultAPIParams(ctx context.Context) {
 	if p.UserAgent == "" {
 		p.UserAgent = "go-splunk"
 	}
-	if p.AuthClient == nil {
-		p.AuthClient = oauth2.NewClient(ctx, p.TokenSource(ctx))
-	}
 	if p.TokenTTL.Nanoseconds() == 0 {
 		// default for Splunk is 60 min, we keep a default 15 min buffer
 		p.TokenTTL = time.Duration(45) * time.Minute
 	}
+	if p.AuthClient == nil && len(p.FailoverURLs) > 0 {
+		p.AuthClient = &http.Client{Transport: newFailoverTransport(ctx, p)}
+	}
+	if p.AuthClient == nil {
+		p.AuthClient = oauth2.NewClient(ctx, p.TokenSource(ctx))
+	}
 }
 
 // TokenSource returns a TokenSource using the configuration
 // in params and the HTTP client from the provided context.
+//
+// If BearerToken is set, it is returned as is.
 func (p *APIParams) TokenSource(ctx context.Context) oauth2.TokenSource {
+	if p.BearerToken != "" {
+		return oauth2.StaticTokenSource(&oauth2.Token{
+			AccessToken: p.BearerToken,
+			TokenType:   "Bearer",
+		})
+	}
 	return oauth2.ReuseTokenSource(nil, splunkSource{ctx, p})
 }
 
@@ -53,12 +76,12 @@ func (p *APIParams) TokenSource(ctx context.Context) oauth2.TokenSource {
 func (p *APIParams) NewClient(ctx context.Context) *Client {
 	p.defaultAPIParams(ctx)
 
-	sling := sling.New().Client(p.AuthClient).Base(p.BaseURL)
+	sling := sling.New().Doer(retryDoer{metricsDoer{p.AuthClient}, p.Retry}).Base(p.BaseURL)
 	// changing output mode requires changing response unmarshalling as well
 	sling.QueryStruct(jsonOutputMode).Set("Accept", "application/json")
 	sling.Set("User-Agent", p.UserAgent)
 
-	return &Client{sling}
+	return &Client{sling, p.Retry}
 }
 
 type splunkSource struct {
@@ -77,9 +100,15 @@ func (ss splunkSource) Token() (*oauth2.Token, error) {
 		// XXX Q: why use oauth2.NewClient in the first place?
 		//     A: to get at the underlying context client
 		AuthClient: oauth2.NewClient(ss.ctx, nil),
+		Retry:      ss.params.Retry,
 	}
 	// one-time use, full API instantiation; however, the token gets cached, and this method is called infrequently
-	resp, err := p.NewAPI(ss.ctx).AccessControl.Authentication.Login(ss.params.ClientID, ss.params.ClientSecret)
+	//
+	// ss.ctx only provides the HTTP client: it may belong to a request that has finished since, and oauth2
+	// does not pass on the context of the request that needs the token.  The login is bounded by the client's
+	// timeout instead.
+	resp, err := p.NewAPI(ss.ctx).AccessControl.Authentication.Login(context.Background(), ss.params.ClientID, ss.params.ClientSecret)
+	tokenRefreshed(err)
 	if err != nil {
 		return nil, err
 	}
@@ -102,7 +131,7 @@ func (ss splunkSource) Token() (*oauth2.Token, error) {
 // Note that query and body values are copied so if pointer values are used,
 // mutating the original value will mutate the value within the child client.
 func (c *Client) New() *Client {
-	return &Client{c.Sling.New()}
+	return &Client{c.Sling.New(), c.retryPolicy}
 }
 
 // Path extends the current API client with the given path by resolving the reference to
@@ -112,22 +141,39 @@ func (c *Client) Path(pathURL string) *Client {
 	return c
 }
 
-// Receive kicks off an API call to the underlying transport.
+// Receive kicks off an API call to the underlying transport, which is canceled when ctx is done.
 // It attempts to deserialize a value into v, if there is neither a transport error nor an API error.
 // Otherwise, the error is returned.
 // This function also returns the full API response.
-func Receive(sling *sling.Sling, v interface{}) (*Response, error) {
+func Receive(ctx context.Context, sling *sling.Sling, v interface{}) (*Response, error) {
+	req, err := sling.Request()
+	if err != nil {
+		return &Response{}, err
+	}
+	return receiveRequest(ctx, sling, req, v)
+}
+
+// receiveRequest is like Receive, but sends req, which has been created from sling.
+func receiveRequest(ctx context.Context, sling *sling.Sling, req *http.Request, v interface{}) (*Response, error) {
 	apiResp := &Response{}
 	apiErr := &APIError{}
-	resp, err := sling.Receive(apiResp, apiErr)
+	resp, err := sling.Do(req.WithContext(ctx), apiResp, apiErr)
 	apiResp.HTTPResponse = resp
-	if err != nil || !apiErr.Empty() {
-		return apiResp, relevantError(err, apiErr)
+	if err := relevantError(resp, err, apiErr); err != nil {
+		return apiResp, err
 	}
 
 	if err = json.Unmarshal(apiResp.Entry, v); err != nil {
-		return apiResp, relevantError(err, apiErr)
+		return apiResp, err
 	}
+	return apiResp, nil
+}
 
-	return apiResp, relevantError(nil, apiErr)
+// receive is like sling.Receive, but sends the request with ctx.
+func receive(ctx context.Context, sling *sling.Sling, successV, failureV interface{}) (*http.Response, error) {
+	req, err := sling.Request()
+	if err != nil {
+		return nil, err
+	}
+	return sling.Do(req.WithContext(ctx), successV, failureV)
 }
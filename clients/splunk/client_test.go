@@ -1,8 +1,14 @@
 package splunk
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"golang.org/x/oauth2"
 	"gotest.tools/v3/assert"
 )
 
@@ -14,3 +20,45 @@ func TestTokenSource_Token(t *testing.T) {
 	assert.NilError(t, err)
 	assert.Assert(t, len(tok.AccessToken) > 0)
 }
+
+func TestTokenSource_BearerToken(t *testing.T) {
+	params := &APIParams{BearerToken: "eyJraWQiOiJzcGx1bmsuc2VjcmV0In0"}
+	tok, err := params.TokenSource(TestDefaultContext()).Token()
+	assert.NilError(t, err)
+	assert.Equal(t, tok.Type(), "Bearer")
+	assert.Equal(t, tok.AccessToken, params.BearerToken)
+}
+
+func TestReceive_Context(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/services/auth/login":
+			_, _ = w.Write([]byte(`{"sessionKey":"key"}`))
+		case "/services/server/info":
+			if r.URL.Query().Get("hang") != "" {
+				<-r.Context().Done()
+				return
+			}
+			_, _ = w.Write([]byte(`{"entry":[{"name":"server-info"}]}`))
+		}
+	}))
+	defer srv.Close()
+
+	// the API outlives the context it was created with
+	apiCtx, cancelAPI := context.WithCancel(context.Background())
+	p := &APIParams{
+		BaseURL: srv.URL,
+		Config:  oauth2.Config{ClientID: "admin", ClientSecret: "secret"},
+	}
+	api := p.NewAPI(apiCtx)
+	cancelAPI()
+	_, _, err := api.Introspection.ServerInfo(context.Background())
+	assert.NilError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = Receive(ctx, api.client.New().Get("server/info").QueryStruct(struct {
+		Hang bool `url:"hang"`
+	}{true}), &[]ServerInfoEntry{})
+	assert.Assert(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+}
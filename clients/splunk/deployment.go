@@ -1,5 +1,7 @@
 package splunk
 
+import "context"
+
 // DeploymentService encapsulates the Deployment portion of the Splunk API
 type DeploymentService struct {
 	client *Client
@@ -15,17 +17,104 @@ var (
 	ServerInfoEntryFilterDefault *PaginationFilter
 
 	ServerInfoEntryFilterMinimal *PaginationFilter = &PaginationFilter{
-		Filter: []string{"host", "host_fqdn", "server_roles"},
+		Filter: []string{"host", "host_fqdn", "server_roles", "serverName", "guid"},
 	}
 )
 
 // SearchPeers returns information about all search peers
-func (d *DeploymentService) SearchPeers(filter *PaginationFilter) ([]ServerInfoEntry, *Response, error) {
-	var info []ServerInfoEntry
-	sling := d.client.New().Get("search/distributed/peers")
-	if filter != ServerInfoEntryFilterDefault {
-		sling = sling.QueryStruct(filter)
+func (d *DeploymentService) SearchPeers(ctx context.Context, filter *PaginationFilter) ([]ServerInfoEntry, *Response, error) {
+	return d.SearchPeersPager(ctx, filter).All()
+}
+
+// SearchPeersPager returns a Pager over all search peers.
+func (d *DeploymentService) SearchPeersPager(ctx context.Context, filter *PaginationFilter) *Pager[ServerInfoEntry] {
+	return newPager[ServerInfoEntry](ctx, filter, getPage(d.client, "search/distributed/peers"))
+}
+
+// ClusterPeerEntry is returned from ClusterPeers() calls.
+type ClusterPeerEntry struct {
+	EntryMetadata
+	Name    string `json:"name"`
+	Content struct {
+		Label        string `json:"label"`
+		HostPortPair string `json:"host_port_pair"`
+		Site         string `json:"site"`
+		Status       string `json:"status"`
+		IsSearchable bool   `json:"is_searchable"`
+	} `json:"content"`
+}
+
+var ClusterPeerEntryFilterDefault *PaginationFilter
+
+// ClusterPeers returns the peers of an indexer cluster.  It must be called on the cluster manager.
+//
+// Splunk 9 renamed cluster/master to cluster/manager; the old endpoint is used if the new one does not exist.
+func (d *DeploymentService) ClusterPeers(ctx context.Context, filter *PaginationFilter) ([]ClusterPeerEntry, *Response, error) {
+	return d.ClusterPeersPager(ctx, filter).All()
+}
+
+// ClusterPeersPager returns a Pager over the peers of an indexer cluster.
+//
+// See also: ClusterPeers
+func (d *DeploymentService) ClusterPeersPager(ctx context.Context, filter *PaginationFilter) *Pager[ClusterPeerEntry] {
+	path := "cluster/manager/peers"
+	return newPager[ClusterPeerEntry](ctx, filter, func(ctx context.Context, filter *PaginationFilter, v interface{}) (*Response, error) {
+		resp, err := getPage(d.client, path)(ctx, filter, v)
+		if path != clusterPeersLegacyPath && IsNotFound(err) {
+			path = clusterPeersLegacyPath
+			resp, err = getPage(d.client, path)(ctx, filter, v)
+		}
+		return resp, err
+	})
+}
+
+// clusterPeersLegacyPath is the endpoint of the cluster peers before Splunk 9.
+const clusterPeersLegacyPath = "cluster/master/peers"
+
+// SHCMemberEntry is returned from SHCMembers() calls.
+type SHCMemberEntry struct {
+	EntryMetadata
+	Name    string `json:"name"`
+	Content struct {
+		Label   string `json:"label"`
+		MgmtURI string `json:"mgmt_uri"`
+		Site    string `json:"site"`
+		Status  string `json:"status"`
+	} `json:"content"`
+}
+
+var SHCMemberEntryFilterDefault *PaginationFilter
+
+// SHCMembers returns the members of a search head cluster.  It must be called on a cluster member.
+func (d *DeploymentService) SHCMembers(ctx context.Context, filter *PaginationFilter) ([]SHCMemberEntry, *Response, error) {
+	return d.SHCMembersPager(ctx, filter).All()
+}
+
+// SHCMembersPager returns a Pager over the members of a search head cluster.
+func (d *DeploymentService) SHCMembersPager(ctx context.Context, filter *PaginationFilter) *Pager[SHCMemberEntry] {
+	return newPager[SHCMemberEntry](ctx, filter, getPage(d.client, "shcluster/member/members"))
+}
+
+// SHCCaptainInfoEntry is returned from SHCCaptainInfo() calls.
+type SHCCaptainInfoEntry struct {
+	EntryMetadata
+	Name    string `json:"name"`
+	Content struct {
+		Label              string `json:"label"`
+		MgmtURI            string `json:"mgmt_uri"`
+		PeerSchemeHostPort string `json:"peer_scheme_host_port"`
+		ElectedCaptain     int64  `json:"elected_captain"`
+		ServiceReadyFlag   bool   `json:"service_ready_flag"`
+	} `json:"content"`
+}
+
+// SHCCaptainInfo returns information about the captain of a search head cluster.  It must be called on a
+// cluster member.
+func (d *DeploymentService) SHCCaptainInfo(ctx context.Context) (*SHCCaptainInfoEntry, *Response, error) {
+	entries := make([]SHCCaptainInfoEntry, 0)
+	resp, err := Receive(ctx, d.client.New().Get("shcluster/captain/info"), &entries)
+	if err != nil || len(entries) == 0 {
+		return nil, resp, err
 	}
-	resp, err := Receive(sling, &info)
-	return info, resp, err
+	return &entries[0], resp, err
 }
@@ -1,9 +1,21 @@
 package splunk
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
 
 // The APIError type encapsulates API errors and status responses.
+//
+// Errors of API calls that received a response other than 2xx are always of this type, even if
+// the response has no messages, e.g., from a proxy.  Use errors.As, or IsNotFound and friends.
 type APIError struct {
+	// Status is the HTTP status code of the response
+	Status int `json:"-"`
+	// Path is the URL path of the request, e.g., "/services/authentication/users/admin"
+	Path     string            `json:"-"`
 	Messages []APIErrorMessage `json:"messages"`
 }
 
@@ -16,12 +28,14 @@ type APIErrorMessage struct {
 
 // Error is an implementation of the error interface
 func (e APIError) Error() string {
-	if len(e.Messages) > 0 {
-		err := e.Messages[0] // XXX concat Messages
-		s := fmt.Sprintf("%s splunk: %s", err.Type, err.Text)
-		return s
+	msgs := make([]string, 0, len(e.Messages))
+	for _, msg := range e.Messages {
+		msgs = append(msgs, fmt.Sprintf("%s splunk: %s", msg.Type, msg.Text))
 	}
-	return ""
+	if len(msgs) == 0 && e.Status != 0 {
+		msgs = append(msgs, fmt.Sprintf("splunk: %d %s", e.Status, http.StatusText(e.Status)))
+	}
+	return strings.Join(msgs, "; ")
 }
 
 // Empty returns true if empty. Otherwise, at least 1 error message is
@@ -30,10 +44,57 @@ func (e APIError) Empty() bool {
 	return len(e.Messages) == 0
 }
 
-// relevantError returns any non-nil http-related error (creating the request,
-// getting the response, decoding) if any. If the decoded apiError is non-zero
-// the apiError is returned. Otherwise, no errors occurred, returns nil.
-func relevantError(httpError error, apiError *APIError) error {
+// IsNotFound returns true if err is an APIError for a missing entity (404).
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized returns true if err is an APIError for missing or invalid credentials (401).
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden returns true if err is an APIError for missing capabilities (403).
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsConflict returns true if err is an APIError for an entity that exists already (409).
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsUnavailable returns true if err is an APIError of a Splunk instance that is unavailable, e.g., restarting,
+// or behind a proxy (502, 503, 504).
+func IsUnavailable(err error) bool {
+	return hasStatus(err, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+}
+
+func hasStatus(err error, codes ...int) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, code := range codes {
+		if apiErr.Status == code {
+			return true
+		}
+	}
+	return false
+}
+
+// relevantError returns an APIError with the status of resp and any decoded messages, if resp is not
+// successful.  Otherwise, it returns any non-nil http-related error (creating the request, getting the
+// response, decoding), or nil if no errors occurred.
+func relevantError(resp *http.Response, httpError error, apiError *APIError) error {
+	if resp != nil && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+		// also if the response could not be decoded
+		apiError.Status = resp.StatusCode
+		if resp.Request != nil {
+			apiError.Path = resp.Request.URL.Path
+		}
+		return apiError
+	}
 	if httpError != nil {
 		return httpError
 	}
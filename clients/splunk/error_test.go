@@ -0,0 +1,84 @@
+package splunk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"golang.org/x/oauth2"
+	"gotest.tools/v3/assert"
+)
+
+func TestAPIError_Error(t *testing.T) {
+	err := APIError{Messages: []APIErrorMessage{
+		{Type: "ERROR", Text: "In handler 'users': Could not find object id=foo"},
+		{Type: "WARN", Text: "more"},
+	}}
+	assert.Error(t, err, "ERROR splunk: In handler 'users': Could not find object id=foo; WARN splunk: more")
+	assert.Error(t, APIError{Status: http.StatusBadGateway}, "splunk: 502 Bad Gateway")
+	assert.Error(t, APIError{}, "")
+}
+
+func TestIsNotFound(t *testing.T) {
+	notFound := &APIError{Status: http.StatusNotFound}
+	assert.Assert(t, IsNotFound(notFound))
+	assert.Assert(t, IsNotFound(fmt.Errorf("error deleting user: %w", notFound)))
+	assert.Assert(t, !IsNotFound(&APIError{Status: http.StatusConflict}))
+	assert.Assert(t, !IsNotFound(errors.New("404")))
+	assert.Assert(t, !IsNotFound(nil))
+
+	// failed logins of the token source are wrapped by the HTTP client
+	unauthorized := &url.Error{Op: "Get", URL: "https://localhost:8089", Err: &APIError{Status: http.StatusUnauthorized}}
+	assert.Assert(t, IsUnauthorized(unauthorized))
+	assert.Assert(t, IsForbidden(&APIError{Status: http.StatusForbidden}))
+	assert.Assert(t, IsConflict(&APIError{Status: http.StatusConflict}))
+	assert.Assert(t, IsUnavailable(&APIError{Status: http.StatusServiceUnavailable}))
+	assert.Assert(t, IsUnavailable(&APIError{Status: http.StatusGatewayTimeout}))
+	assert.Assert(t, !IsUnavailable(&APIError{Status: http.StatusInternalServerError}))
+}
+
+func TestReceive_APIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/services/auth/login":
+			_, _ = w.Write([]byte(`{"sessionKey":"key"}`))
+		case "/services/authorization/roles":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"messages":[{"type":"ERROR","text":"Role exists"}]}`))
+		case "/services/properties/server/general/foo":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<response><messages><msg type="ERROR">foo does not exist</msg></messages></response>`))
+		default:
+			// like a proxy
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(`<html>Bad Gateway</html>`))
+		}
+	}))
+	defer srv.Close()
+	p := &APIParams{
+		BaseURL: srv.URL,
+		Config:  oauth2.Config{ClientID: "admin", ClientSecret: "secret"},
+	}
+	api := p.NewAPI(context.Background())
+
+	_, _, err := api.AccessControl.Authorization.Roles.Create(context.Background(), &CreateRoleOptions{Name: "role1"})
+	var apiErr *APIError
+	assert.Assert(t, errors.As(err, &apiErr))
+	assert.Equal(t, apiErr.Status, http.StatusConflict)
+	assert.Equal(t, apiErr.Path, "/services/authorization/roles")
+	assert.Assert(t, IsConflict(err))
+	assert.Error(t, err, "ERROR splunk: Role exists")
+
+	_, _, err = api.Introspection.ServerInfo(context.Background())
+	assert.Assert(t, IsUnavailable(err))
+	assert.Error(t, err, "splunk: 502 Bad Gateway")
+
+	_, _, err = api.Properties.GetKey(context.Background(), "server", "general", "foo")
+	assert.Assert(t, IsNotFound(err))
+	assert.Assert(t, errors.As(err, &apiErr))
+	assert.Equal(t, apiErr.Path, "/services/properties/server/general/foo")
+}
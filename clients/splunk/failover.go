@@ -0,0 +1,209 @@
+package splunk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// DefaultFailoverRecovery is the default time after which an unavailable endpoint is checked again.
+const DefaultFailoverRecovery = time.Minute
+
+// failoverEndpoint is one of the Splunk instances an API with FailoverURLs talks to.
+//
+// Session tokens are specific to a Splunk instance, hence every endpoint has its own token source.
+type failoverEndpoint struct {
+	baseURL   *url.URL
+	transport http.RoundTripper
+	api       *API // for health checks
+
+	// zero if the endpoint is considered available
+	retryAt time.Time
+}
+
+// failoverTransport sends requests to the first healthy endpoint, in the configured order.
+//
+// Requests are built against the primary endpoint (APIParams.BaseURL), and rewritten for the
+// endpoint actually used.  An endpoint that fails with a transport error or with one of the
+// status codes in failoverStatusCodes is skipped until APIParams.FailoverRecovery has passed;
+// it is used again once Introspection.ServerInfo succeeds.
+//
+// After a transport error, only idempotent requests are sent to the next endpoint, unless no
+// connection could be established: other requests might have been applied already.
+type failoverTransport struct {
+	endpoints []*failoverEndpoint
+	recovery  time.Duration
+
+	mu     sync.Mutex
+	active int
+}
+
+// failoverStatusCodes are returned by proxies and load balancers in front of an unavailable Splunk instance,
+// and by Splunk itself while restarting.
+var failoverStatusCodes = map[int]bool{
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+func newFailoverTransport(ctx context.Context, p *APIParams) *failoverTransport {
+	t := &failoverTransport{recovery: p.FailoverRecovery}
+	if t.recovery == 0 {
+		t.recovery = DefaultFailoverRecovery
+	}
+	for _, baseURL := range append([]string{p.BaseURL}, p.FailoverURLs...) {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			// like invalid base URLs, which sling ignores
+			continue
+		}
+		endpointParams := *p
+		endpointParams.BaseURL = baseURL
+		endpointParams.FailoverURLs = nil
+		endpointParams.AuthClient = oauth2.NewClient(ctx, endpointParams.TokenSource(ctx))
+		t.endpoints = append(t.endpoints, &failoverEndpoint{
+			baseURL:   u,
+			transport: endpointParams.AuthClient.Transport,
+			api:       endpointParams.NewAPI(ctx),
+		})
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	candidates := t.candidates(req, time.Now())
+	for i, endpoint := range candidates {
+		r, err := t.rewrite(req, endpoint, i > 0)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := endpoint.transport.RoundTrip(r)
+		if err == nil && !failoverStatusCodes[resp.StatusCode] {
+			t.succeeded(endpoint)
+			return resp, nil
+		}
+		t.failed(endpoint)
+		if i == len(candidates)-1 || !canRetry(req) || (err != nil && !canFailover(req, err)) {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
+	return nil, fmt.Errorf("no endpoint available")
+}
+
+// candidates returns the endpoints to try, in order.  Endpoints before the active one, which are due
+// for recovery, are health-checked first.  Unavailable endpoints are tried as a last resort.
+func (t *failoverTransport) candidates(req *http.Request, now time.Time) []*failoverEndpoint {
+	t.mu.Lock()
+	var due []*failoverEndpoint
+	for i, endpoint := range t.endpoints {
+		if i < t.active && !endpoint.retryAt.IsZero() && !now.Before(endpoint.retryAt) {
+			due = append(due, endpoint)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, endpoint := range due {
+		t.check(req.Context(), endpoint, now)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var available, unavailable []*failoverEndpoint
+	for i, endpoint := range t.endpoints {
+		// endpoints after the active one are only used if it fails
+		if endpoint.retryAt.IsZero() || (i >= t.active && !now.Before(endpoint.retryAt)) {
+			available = append(available, endpoint)
+		} else {
+			unavailable = append(unavailable, endpoint)
+		}
+	}
+	return append(available, unavailable...)
+}
+
+// check health-checks endpoint, and records the result.
+func (t *failoverTransport) check(ctx context.Context, endpoint *failoverEndpoint, now time.Time) {
+	_, _, err := endpoint.api.Introspection.ServerInfo(ctx)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		endpoint.retryAt = now.Add(t.recovery)
+	} else {
+		endpoint.retryAt = time.Time{}
+	}
+}
+
+func (t *failoverTransport) succeeded(endpoint *failoverEndpoint) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	endpoint.retryAt = time.Time{}
+	for i := range t.endpoints {
+		if t.endpoints[i] == endpoint {
+			t.active = i
+			break
+		}
+	}
+}
+
+// failed marks endpoint as unavailable.
+func (t *failoverTransport) failed(endpoint *failoverEndpoint) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	endpoint.retryAt = time.Now().Add(t.recovery)
+}
+
+// rewrite returns a copy of req for endpoint.
+func (t *failoverTransport) rewrite(req *http.Request, endpoint *failoverEndpoint, retry bool) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	primary := t.endpoints[0].baseURL
+	r.URL.Scheme = endpoint.baseURL.Scheme
+	r.URL.Host = endpoint.baseURL.Host
+	r.URL.Path = strings.TrimSuffix(endpoint.baseURL.Path, "/") + "/" +
+		strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, strings.TrimSuffix(primary.Path, "/")), "/")
+	r.URL.RawPath = ""
+	r.Host = ""
+	if retry && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// canRetry returns true if the body of req, if any, can be sent again.
+func canRetry(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// canFailover returns true if req may be sent to another endpoint after failing with the transport error err.
+func canFailover(req *http.Request, err error) bool {
+	var opErr *net.OpError
+	return isIdempotent(req.Method) || (errors.As(err, &opErr) && opErr.Op == "dial")
+}
+
+// ActiveURL returns the base URL of the endpoint currently in use.
+//
+// For an API without FailoverURLs, this is always the BaseURL.
+func (api *API) ActiveURL() string {
+	t, ok := api.params.AuthClient.Transport.(*failoverTransport)
+	if !ok {
+		return api.params.BaseURL
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.endpoints[t.active].baseURL.String()
+}
@@ -0,0 +1,135 @@
+package splunk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"gotest.tools/v3/assert"
+)
+
+// testFailoverServer serves just enough of the API for logging in and health checks.
+func testFailoverServer(t *testing.T, name string, down *int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(down) != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/services/auth/login":
+			_, _ = w.Write([]byte(`{"sessionKey":"` + name + `"}`))
+		case "/services/server/info":
+			if r.Header.Get("Authorization") != "Splunk "+name {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"entry":[{"name":"server-info","content":{"serverName":"` + name + `"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAPI_failover(t *testing.T) {
+	var primaryDown, secondaryDown int32
+	primary := testFailoverServer(t, "primary", &primaryDown)
+	secondary := testFailoverServer(t, "secondary", &secondaryDown)
+
+	p := &APIParams{
+		BaseURL:          primary.URL,
+		FailoverURLs:     []string{secondary.URL},
+		FailoverRecovery: time.Millisecond,
+		Config:           oauth2.Config{ClientID: "admin", ClientSecret: "secret"},
+	}
+	api := p.NewAPI(context.Background())
+
+	serverName := func() string {
+		t.Helper()
+		info, _, err := api.Introspection.ServerInfo(context.Background())
+		assert.NilError(t, err)
+		assert.Equal(t, len(info), 1)
+		return info[0].Content.ServerName
+	}
+	assert.Equal(t, serverName(), "primary")
+	assert.Equal(t, api.ActiveURL(), primary.URL)
+
+	atomic.StoreInt32(&primaryDown, 1)
+	assert.Equal(t, serverName(), "secondary")
+	assert.Equal(t, api.ActiveURL(), secondary.URL)
+
+	// recovery is only attempted after FailoverRecovery
+	atomic.StoreInt32(&primaryDown, 0)
+	time.Sleep(2 * time.Millisecond)
+	assert.Equal(t, serverName(), "primary")
+	assert.Equal(t, api.ActiveURL(), primary.URL)
+
+	atomic.StoreInt32(&primaryDown, 1)
+	atomic.StoreInt32(&secondaryDown, 1)
+	_, resp, err := api.Introspection.ServerInfo(context.Background())
+	assert.Assert(t, err != nil)
+	assert.Equal(t, resp.HTTPResponse.StatusCode, http.StatusServiceUnavailable)
+}
+
+func TestAPI_failoverNonIdempotent(t *testing.T) {
+	var secondaryDown, secondaryPosts int32
+	secondaryAPI := testFailoverServer(t, "secondary", &secondaryDown)
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path != "/services/auth/login" {
+			atomic.AddInt32(&secondaryPosts, 1)
+		}
+		secondaryAPI.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(secondary.Close)
+
+	// the primary drops connections after receiving requests, which it might have applied
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/services/auth/login" {
+			_, _ = w.Write([]byte(`{"sessionKey":"primary"}`))
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	t.Cleanup(primary.Close)
+	newClient := func(primaryURL string) *http.Client {
+		p := &APIParams{
+			BaseURL:      primaryURL,
+			FailoverURLs: []string{secondary.URL},
+			Config:       oauth2.Config{ClientID: "admin", ClientSecret: "secret"},
+		}
+		p.NewAPI(context.Background())
+		return p.AuthClient
+	}
+	post := func(client *http.Client, primaryURL string) error {
+		resp, err := client.Post(primaryURL+"/services/authentication/users", "application/x-www-form-urlencoded",
+			strings.NewReader("name=test"))
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	assert.Assert(t, post(newClient(primary.URL), primary.URL) != nil)
+	assert.Equal(t, atomic.LoadInt32(&secondaryPosts), int32(0))
+
+	// idempotent requests fail over
+	resp, err := newClient(primary.URL).Get(primary.URL + "/services/server/info")
+	assert.NilError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	// requests that could not be sent at all fail over
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	assert.NilError(t, post(newClient(unreachable.URL), unreachable.URL))
+	assert.Equal(t, atomic.LoadInt32(&secondaryPosts), int32(1))
+}
@@ -0,0 +1,96 @@
+package splunk
+
+import (
+	"context"
+	"net/url"
+)
+
+// HECService encapsulates the HTTP Event Collector portion of the Splunk API.
+type HECService struct {
+	client *Client
+}
+
+func newHECService(client *Client) *HECService {
+	return &HECService{
+		client: client.Path("data/inputs/"),
+	}
+}
+
+// HECEntry is returned from Inputs() calls.
+//
+// The entry name is "http://" followed by the name of the input.
+type HECEntry struct {
+	EntryMetadata
+	Name    string `json:"name"`
+	Content struct {
+		Disabled   bool     `json:"disabled"`
+		Host       string   `json:"host"`
+		Index      string   `json:"index"`
+		Indexes    []string `json:"indexes"`
+		Source     string   `json:"source"`
+		Sourcetype string   `json:"sourcetype"`
+		Token      string   `json:"token"`
+	} `json:"content"`
+}
+
+var HECEntryFilterDefault *PaginationFilter
+
+// Inputs returns information about all HTTP Event Collector inputs matching filter.
+func (s *HECService) Inputs(ctx context.Context, filter *PaginationFilter) ([]HECEntry, *Response, error) {
+	return s.InputsPager(ctx, filter).All()
+}
+
+// InputsPager returns a Pager over all HTTP Event Collector inputs matching filter.
+func (s *HECService) InputsPager(ctx context.Context, filter *PaginationFilter) *Pager[HECEntry] {
+	return newPager[HECEntry](ctx, filter, getPage(s.client, "http"))
+}
+
+// The CreateHECOptions type provides options for creating a new HTTP Event Collector input.
+type CreateHECOptions struct {
+	Name        string   `url:"name"`
+	Description string   `url:"description,omitempty"`
+	Disabled    *bool    `url:"disabled,omitempty"`
+	Host        string   `url:"host,omitempty"`
+	Index       string   `url:"index,omitempty"`
+	Indexes     []string `url:"indexes,omitempty"`
+	Source      string   `url:"source,omitempty"`
+	Sourcetype  string   `url:"sourcetype,omitempty"`
+	// Token is generated by Splunk, if empty.
+	Token  string `url:"token,omitempty"`
+	UseACK *bool  `url:"useACK,omitempty"`
+}
+
+// Create creates a new HTTP Event Collector input, and returns additional meta data, including the token.
+func (s *HECService) Create(ctx context.Context, opts *CreateHECOptions) (*HECEntry, *Response, error) {
+	inputs := make([]HECEntry, 0)
+	resp, err := Receive(ctx, s.client.New().BodyForm(opts).Post("http"), &inputs)
+	if err != nil || len(inputs) == 0 {
+		return nil, resp, err
+	}
+	return &inputs[0], resp, err
+}
+
+// Enable enables an HTTP Event Collector input, and returns additional meta data.
+func (s *HECService) Enable(ctx context.Context, name string) (*HECEntry, *Response, error) {
+	return s.post(ctx, name, "enable")
+}
+
+// Disable disables an HTTP Event Collector input, and returns additional meta data.
+func (s *HECService) Disable(ctx context.Context, name string) (*HECEntry, *Response, error) {
+	return s.post(ctx, name, "disable")
+}
+
+func (s *HECService) post(ctx context.Context, name, action string) (*HECEntry, *Response, error) {
+	inputs := make([]HECEntry, 0)
+	resp, err := Receive(ctx, s.client.New().Path("http/"+url.PathEscape(name)+"/").Post(action), &inputs)
+	if err != nil || len(inputs) == 0 {
+		return nil, resp, err
+	}
+	return &inputs[0], resp, err
+}
+
+// Delete deletes an HTTP Event Collector input, and returns additional meta data.
+func (s *HECService) Delete(ctx context.Context, name string) (*Response, error) {
+	inputs := make([]HECEntry, 0)
+	return Receive(ctx, s.client.New().Path("http/").Delete(url.PathEscape(name)), &inputs)
+}
@@ -0,0 +1,78 @@
+package splunk
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestHECService_Create(t *testing.T) {
+	hecSvc := testHECService(t)
+	params := testHECParams()
+
+	input, _, err := hecSvc.Create(context.Background(), params)
+	assert.NilError(t, err)
+	// nolint:errcheck
+	defer hecSvc.Delete(context.Background(), params.Name)
+	assert.Equal(t, input.Name, "http://"+params.Name)
+	assert.Equal(t, input.Content.Index, params.Index)
+	assert.Equal(t, input.Content.Sourcetype, params.Sourcetype)
+	assert.Assert(t, input.Content.Token != "")
+
+	inputs, _, err := hecSvc.Inputs(context.Background(), HECEntryFilterDefault)
+	assert.NilError(t, err)
+	found := false
+	for ii := range inputs {
+		if inputs[ii].Name == input.Name {
+			found = true
+			assert.Equal(t, inputs[ii].Content.Token, input.Content.Token)
+		}
+	}
+	assert.Assert(t, found)
+}
+
+func TestHECService_Disable(t *testing.T) {
+	hecSvc := testHECService(t)
+	params := testHECParams()
+
+	_, _, err := hecSvc.Create(context.Background(), params)
+	assert.NilError(t, err)
+	// nolint:errcheck
+	defer hecSvc.Delete(context.Background(), params.Name)
+
+	input, _, err := hecSvc.Disable(context.Background(), params.Name)
+	assert.NilError(t, err)
+	assert.Assert(t, input.Content.Disabled)
+
+	input, _, err = hecSvc.Enable(context.Background(), params.Name)
+	assert.NilError(t, err)
+	assert.Assert(t, !input.Content.Disabled)
+}
+
+func TestHECService_Delete(t *testing.T) {
+	hecSvc := testHECService(t)
+	params := testHECParams()
+
+	_, _, err := hecSvc.Create(context.Background(), params)
+	assert.NilError(t, err)
+
+	_, err = hecSvc.Delete(context.Background(), params.Name)
+	assert.NilError(t, err)
+}
+
+// Helpers
+func testHECService(t *testing.T) *HECService {
+	api := TestGlobalSplunkClient(t)
+	TestEnableHEC(t, api)
+	return api.HEC
+}
+
+func testHECParams() *CreateHECOptions {
+	return &CreateHECOptions{
+		Name:       testNewUsername("testhec-"),
+		Index:      "main",
+		Indexes:    []string{"main"},
+		Sourcetype: "test",
+	}
+}
@@ -1,5 +1,7 @@
 package splunk
 
+import "context"
+
 // IntrospectionService encapsulates the Introspection portion of the Splunk API.
 type IntrospectionService struct {
 	client *Client
@@ -17,15 +19,24 @@ func newIntrospectionService(client *Client) *IntrospectionService {
 type ServerInfoEntry struct {
 	EntryMetadata
 	Content struct {
-		ActiveLicenseGroup string `json:"activeLicenseGroup"`
+		ActiveLicenseGroup    string `json:"activeLicenseGroup"`
+		ActiveLicenseSubgroup string `json:"activeLicenseSubgroup"`
 		// XXX ...
-		Build    string `json:"build"`
-		CPUArch  string `json:"cpu_arch"`
-		GUID     string `json:"guid"`
-		Host     string `json:"host"`
-		HostFQDN string `json:"host_fqdn"`
-		IsFree   bool   `json:"isFree"`
-		IsTrial  bool   `json:"isTrial"`
+		Build         string   `json:"build"`
+		CPUArch       string   `json:"cpu_arch"`
+		GUID          string   `json:"guid"`
+		HealthInfo    string   `json:"health_info"`
+		Host          string   `json:"host"`
+		HostFQDN      string   `json:"host_fqdn"`
+		IsFree        bool     `json:"isFree"`
+		IsTrial       bool     `json:"isTrial"`
+		KVStoreStatus string   `json:"kvStoreStatus"`
+		LicenseLabels []string `json:"license_labels"`
+		LicenseState  string   `json:"licenseState"`
+		MasterGUID    string   `json:"master_guid"`
+		Mode          string   `json:"mode"`
+		OSName        string   `json:"os_name"`
+		ProductType   string   `json:"product_type"`
 		// XXX ...
 		Roles       []string  `json:"server_roles"`
 		ServerName  string    `json:"serverName"`
@@ -35,8 +46,8 @@ type ServerInfoEntry struct {
 }
 
 // ServerInfo returns information about the Splunk instance.
-func (s *IntrospectionService) ServerInfo() ([]ServerInfoEntry, *Response, error) {
+func (s *IntrospectionService) ServerInfo(ctx context.Context) ([]ServerInfoEntry, *Response, error) {
 	info := make([]ServerInfoEntry, 0)
-	resp, err := Receive(s.client.New().Get("server/info"), &info)
+	resp, err := Receive(ctx, s.client.New().Get("server/info"), &info)
 	return info, resp, err
 }
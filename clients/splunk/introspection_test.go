@@ -1,6 +1,7 @@
 package splunk
 
 import (
+	"context"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -13,7 +14,7 @@ func testIntrospectionService(t *testing.T) *IntrospectionService {
 func TestIntrospectionService_ServerInfo(t *testing.T) {
 	s := testIntrospectionService(t)
 
-	info, resp, err := s.ServerInfo()
+	info, resp, err := s.ServerInfo(context.Background())
 	assert.NilError(t, err)
 	assert.Equal(t, len(info), 1)
 	assert.Assert(t, info[0].ID != "")
@@ -0,0 +1,69 @@
+package splunk
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+)
+
+// Telemetry is reported via the global go-metrics instance.
+var (
+	metricRequest      = []string{"splunk", "api", "request"}
+	metricTokenRefresh = []string{"splunk", "auth", "token_refresh"}
+)
+
+// metricsDoer reports the latency and status of API requests.
+type metricsDoer struct {
+	client *http.Client
+}
+
+func (d metricsDoer) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := d.client.Do(req)
+	status := "error"
+	if resp != nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.MeasureSinceWithLabels(metricRequest, start, []metrics.Label{
+		{Name: "method", Value: req.Method},
+		{Name: "endpoint", Value: endpointLabel(req.URL.Path)},
+		{Name: "status", Value: status},
+	})
+	return resp, err
+}
+
+// threeLevelEndpoints are the first path segments of endpoints like "cluster/manager/peers".
+var threeLevelEndpoints = map[string]bool{
+	"cluster":   true,
+	"data":      true,
+	"search":    true,
+	"shcluster": true,
+}
+
+// endpointLabel returns the endpoint of an API request path, without names of individual entities
+// like users, to keep the cardinality of metrics low.
+func endpointLabel(path string) string {
+	if i := strings.Index(path, "/services/"); i >= 0 {
+		path = path[i+len("/services/"):]
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	n := 2
+	if threeLevelEndpoints[segments[0]] {
+		n = 3
+	}
+	if len(segments) > n {
+		segments = segments[:n]
+	}
+	return strings.Join(segments, "/")
+}
+
+func tokenRefreshed(err error) {
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	metrics.IncrCounterWithLabels(metricTokenRefresh, 1, []metrics.Label{{Name: "status", Value: status}})
+}
@@ -0,0 +1,24 @@
+package splunk
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_endpointLabel(t *testing.T) {
+	tests := map[string]string{
+		"/services/server/info":                               "server/info",
+		"/services/auth/login":                                "auth/login",
+		"/services/authentication/users/vault_abc":            "authentication/users",
+		"/services/authorization/tokens/admin":                "authorization/tokens",
+		"/services/data/inputs/http/vault_abc/disable":        "data/inputs/http",
+		"/services/cluster/manager/peers":                     "cluster/manager/peers",
+		"/services/properties/server/general/pass4SymmKey":    "properties/server",
+		"/splunk/services/shcluster/member/members":           "shcluster/member/members",
+		"/services/search/distributed/peers/idx1.example.com": "search/distributed/peers",
+	}
+	for path, expected := range tests {
+		assert.Equal(t, endpointLabel(path), expected, path)
+	}
+}
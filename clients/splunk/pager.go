@@ -0,0 +1,117 @@
+package splunk
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/dghubble/sling"
+)
+
+// DefaultPageSize is the number of entries a Pager requests at a time, unless the filter sets Count.
+const DefaultPageSize = 1000
+
+// pageFunc requests the entries selected by filter into v.
+type pageFunc func(ctx context.Context, filter *PaginationFilter, v interface{}) (*Response, error)
+
+// A Pager iterates over the entries of a collection, one page at a time.
+//
+// The search, sort and field filters of the PaginationFilter apply to all pages; Count sets the page size
+// (default: DefaultPageSize), and Offset the first entry.  Typical use:
+//
+//	pager := api.AccessControl.Authentication.Users.UsersPager(ctx, filter)
+//	for pager.Next() {
+//		for _, user := range pager.Page() {
+//			...
+//		}
+//	}
+//	if err := pager.Err(); err != nil {
+//		...
+//	}
+//
+// Entries are paged by offset; collections modified during the iteration may skip or repeat entries.
+type Pager[T any] struct {
+	ctx    context.Context
+	fetch  pageFunc
+	filter PaginationFilter
+
+	page []T
+	resp *Response
+	err  error
+	done bool
+}
+
+func newPager[T any](ctx context.Context, filter *PaginationFilter, fetch pageFunc) *Pager[T] {
+	p := &Pager[T]{ctx: ctx, fetch: fetch}
+	if filter != nil {
+		p.filter = *filter
+	}
+	if p.filter.Count <= 0 {
+		p.filter.Count = DefaultPageSize
+	}
+	return p
+}
+
+// Next requests the next page.  It returns false if there are no more entries, or if an error occurred.
+func (p *Pager[T]) Next() bool {
+	if p.done {
+		return false
+	}
+	page := make([]T, 0)
+	resp, err := p.fetch(p.ctx, &p.filter, &page)
+	p.page, p.resp, p.err = page, resp, err
+	if err != nil {
+		p.page = nil
+		p.done = true
+		return false
+	}
+	p.filter.Offset += len(page)
+	// endpoints without paging information return all entries at once
+	if len(page) < p.filter.Count || p.filter.Offset >= resp.Paging.Total {
+		p.done = true
+	}
+	return len(page) > 0
+}
+
+// Page returns the entries of the current page.
+func (p *Pager[T]) Page() []T {
+	return p.page
+}
+
+// Response returns the response of the last request.
+func (p *Pager[T]) Response() *Response {
+	return p.resp
+}
+
+// Err returns the error of the last request, if any.
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// All returns the remaining entries of all pages, and the response of the last request.
+func (p *Pager[T]) All() ([]T, *Response, error) {
+	entries := make([]T, 0)
+	for p.Next() {
+		entries = append(entries, p.page...)
+	}
+	return entries, p.resp, p.err
+}
+
+// getPage returns a pageFunc for the collection at path, relative to client.
+func getPage(client *Client, path string) pageFunc {
+	return func(ctx context.Context, filter *PaginationFilter, v interface{}) (*Response, error) {
+		return receivePage(ctx, client.New().Get(path), filter, v)
+	}
+}
+
+// receivePage is like Receive, but requests the entries selected by filter.
+func receivePage(ctx context.Context, sling *sling.Sling, filter *PaginationFilter, v interface{}) (*Response, error) {
+	req, err := sling.QueryStruct(filter).Request()
+	if err != nil {
+		return &Response{}, err
+	}
+	// jsonOutputMode requests all entries (count=0); the page size takes precedence
+	query := req.URL.Query()
+	query.Set("count", strconv.Itoa(filter.Count))
+	req.URL.RawQuery = query.Encode()
+	return receiveRequest(ctx, sling, req, v)
+}
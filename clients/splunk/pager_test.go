@@ -0,0 +1,115 @@
+package splunk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"golang.org/x/oauth2"
+	"gotest.tools/v3/assert"
+)
+
+// testPagedServer serves total entries named "<prefix>N" at path, honoring count and offset.
+func testPagedServer(t *testing.T, path, prefix string, total int, requests *[]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/services/auth/login" {
+			_, _ = w.Write([]byte(`{"sessionKey":"key"}`))
+			return
+		}
+		if r.URL.Path != path {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"messages":[{"type":"ERROR","text":"Not Found"}]}`))
+			return
+		}
+		*requests = append(*requests, r.URL.RawQuery)
+		query := r.URL.Query()
+		count, _ := strconv.Atoi(query.Get("count"))
+		offset, _ := strconv.Atoi(query.Get("offset"))
+		entries := make([]map[string]string, 0)
+		for i := offset; i < total && i < offset+count; i++ {
+			entries = append(entries, map[string]string{"name": fmt.Sprintf("%s%d", prefix, i)})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"entry":  entries,
+			"paging": Paging{Offset: offset, PerPage: count, Total: total},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testPagedAPI(srv *httptest.Server) *API {
+	p := &APIParams{
+		BaseURL: srv.URL,
+		Config:  oauth2.Config{ClientID: "admin", ClientSecret: "secret"},
+	}
+	return p.NewAPI(context.Background())
+}
+
+func TestPager(t *testing.T) {
+	var requests []string
+	srv := testPagedServer(t, "/services/authentication/users", "user", 5, &requests)
+	users := testPagedAPI(srv).AccessControl.Authentication.Users
+
+	pager := users.UsersPager(context.Background(), &PaginationFilter{Count: 2, Search: "name=user*", SortKey: "name"})
+	var pages [][]string
+	for pager.Next() {
+		var names []string
+		for _, user := range pager.Page() {
+			names = append(names, user.Name)
+		}
+		pages = append(pages, names)
+	}
+	assert.NilError(t, pager.Err())
+	assert.DeepEqual(t, pages, [][]string{{"user0", "user1"}, {"user2", "user3"}, {"user4"}})
+	assert.Equal(t, pager.Response().Paging.Total, 5)
+	assert.DeepEqual(t, requests, []string{
+		"count=2&output_mode=json&search=name%3Duser%2A&sort_key=name",
+		"count=2&offset=2&output_mode=json&search=name%3Duser%2A&sort_key=name",
+		"count=2&offset=4&output_mode=json&search=name%3Duser%2A&sort_key=name",
+	})
+	assert.Assert(t, !pager.Next())
+
+	// pages of exactly count entries
+	requests = nil
+	all, _, err := users.UsersPager(context.Background(), &PaginationFilter{Count: 5}).All()
+	assert.NilError(t, err)
+	assert.Equal(t, len(all), 5)
+	assert.Equal(t, len(requests), 1)
+
+	// default page size
+	requests = nil
+	all, _, err = users.Users(context.Background(), UserEntryFilterDefault)
+	assert.NilError(t, err)
+	assert.Equal(t, len(all), 5)
+	assert.DeepEqual(t, requests, []string{fmt.Sprintf("count=%d&output_mode=json", DefaultPageSize)})
+}
+
+func TestPager_error(t *testing.T) {
+	var requests []string
+	srv := testPagedServer(t, "/services/authentication/users", "user", 5, &requests)
+	roles := testPagedAPI(srv).AccessControl.Authorization.Roles
+
+	pager := roles.RolesPager(context.Background(), RoleEntryFilterDefault)
+	assert.Assert(t, !pager.Next())
+	assert.ErrorContains(t, pager.Err(), "Not Found")
+	assert.Assert(t, pager.Page() == nil)
+	assert.Equal(t, pager.Response().HTTPResponse.StatusCode, http.StatusNotFound)
+}
+
+func TestDeploymentService_ClusterPeersPager(t *testing.T) {
+	var requests []string
+	srv := testPagedServer(t, "/services/cluster/master/peers", "peer", 3, &requests)
+	deployment := testPagedAPI(srv).Deployment
+
+	peers, _, err := deployment.ClusterPeersPager(context.Background(), &PaginationFilter{Count: 2}).All()
+	assert.NilError(t, err)
+	assert.Equal(t, len(peers), 3)
+	assert.Equal(t, peers[2].Name, "peer2")
+	assert.Equal(t, len(requests), 2)
+}
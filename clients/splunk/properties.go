@@ -1,6 +1,7 @@
 package splunk
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -49,29 +50,28 @@ func (d stringResponseDecoder) Decode(resp *http.Response, v interface{}) error
 		vVal.Elem().Set(tempVal.Elem())
 		return nil
 	}
-	return json.Unmarshal(body, v)
+	// error responses without messages, e.g., in XML, are reported by status (see relevantError)
+	_ = json.Unmarshal(body, v)
+	return nil
 }
 
 // UpdateKey updates value for specified key from the specified stanza in the configuration file
-func (p *PropertiesService) UpdateKey(file string, stanza string, key string, value string) (*string, *http.Response, error) {
+func (p *PropertiesService) UpdateKey(ctx context.Context, file string, stanza string, key string, value string) (*string, *http.Response, error) {
 	apiError := &APIError{}
-	body := strings.NewReader(fmt.Sprintf("value=%s", value))
-	resp, err := p.client.New().Post(
-		getPropertiesUri(file, stanza, key)).Body(body).ResponseDecoder(stringResponseDecoder{}).Receive(nil, apiError)
-	if err != nil || !apiError.Empty() {
-		return nil, resp, relevantError(err, apiError)
-	}
-	return nil, resp, relevantError(err, apiError)
+	body := strings.NewReader(url.Values{"value": {value}}.Encode())
+	resp, err := receive(ctx, p.client.New().Post(
+		getPropertiesUri(file, stanza, key)).Body(body).ResponseDecoder(stringResponseDecoder{}), nil, apiError)
+	return nil, resp, relevantError(resp, err, apiError)
 }
 
 // GetKey returns value for the given key from the specified stanza in the configuration file
-func (p *PropertiesService) GetKey(file string, stanza string, key string) (*string, *http.Response, error) {
+func (p *PropertiesService) GetKey(ctx context.Context, file string, stanza string, key string) (*string, *http.Response, error) {
 	apiError := &APIError{}
 	output := &Entry{}
-	resp, err := p.client.New().Get(
-		getPropertiesUri(file, stanza, key)).ResponseDecoder(stringResponseDecoder{}).Receive(output, apiError)
-	if err != nil || !apiError.Empty() {
-		return nil, resp, relevantError(err, apiError)
+	resp, err := receive(ctx, p.client.New().Get(
+		getPropertiesUri(file, stanza, key)).ResponseDecoder(stringResponseDecoder{}), output, apiError)
+	if err := relevantError(resp, err, apiError); err != nil {
+		return nil, resp, err
 	}
-	return &output.Value, resp, relevantError(err, apiError)
+	return &output.Value, resp, nil
 }
@@ -0,0 +1,165 @@
+package splunk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dghubble/sling"
+)
+
+// Defaults for RetryPolicy.
+const (
+	DefaultRetryBackoff    = time.Second
+	DefaultRetryMaxBackoff = 30 * time.Second
+)
+
+// DefaultRetryStatusCodes are the status codes of transient failures, e.g., while splunkd restarts.
+var DefaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy configures retries of API calls failing with transport errors, or with one of StatusCodes.
+//
+// Idempotent requests (GET, HEAD, DELETE) are retried transparently.  Of the other calls, only
+// AuthenticationService.Login and UserService.Create are retried; the latter only if the user
+// does not exist after the failed attempt.
+type RetryPolicy struct {
+	// MaxAttempts includes the first attempt; 0 or 1 disable retries
+	MaxAttempts int
+	// Backoff is the time before the first retry, which doubles with every further attempt up to MaxBackoff.
+	// A Retry-After header takes precedence, up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// StatusCodes default to DefaultRetryStatusCodes
+	StatusCodes []int
+}
+
+// retryable returns true if a failed attempt should be retried.
+func (p *RetryPolicy) retryable(attempt int, resp *http.Response, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	if resp == nil {
+		// a failed login is no transport error
+		var apiErr *APIError
+		return err != nil && !errors.As(err, &apiErr)
+	}
+	codes := p.StatusCodes
+	if len(codes) == 0 {
+		codes = DefaultRetryStatusCodes
+	}
+	for _, code := range codes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the time to wait after attempt failed with resp, which may be nil.
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	maxBackoff := p.MaxBackoff
+	if maxBackoff == 0 {
+		maxBackoff = DefaultRetryMaxBackoff
+	}
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			if retryAfter > maxBackoff {
+				return maxBackoff
+			}
+			return retryAfter
+		}
+	}
+	backoff := p.Backoff
+	if backoff == 0 {
+		backoff = DefaultRetryBackoff
+	}
+	for i := 1; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
+
+// parseRetryAfter parses a Retry-After header, which is either in seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// retryDoer retries idempotent requests according to policy.
+type retryDoer struct {
+	next   sling.Doer
+	policy *RetryPolicy
+}
+
+func (d retryDoer) Do(req *http.Request) (*http.Response, error) {
+	if d.policy == nil || !isIdempotent(req.Method) {
+		return d.next.Do(req)
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := d.next.Do(req)
+		if req.Context().Err() != nil || !d.policy.retryable(attempt, resp, err) {
+			return resp, err
+		}
+		backoff := d.policy.backoff(attempt, resp)
+		if resp != nil {
+			// allow reusing the connection
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if !sleep(req.Context(), backoff) {
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+func isIdempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodDelete
+}
+
+// retry waits before the next attempt of a non-idempotent call, if attempt failed transiently
+// with resp or err.  It returns false if the call should not be retried, or if ctx is done.
+func (c *Client) retry(ctx context.Context, attempt int, resp *Response, err error) bool {
+	var httpResp *http.Response
+	if resp != nil {
+		httpResp = resp.HTTPResponse
+	}
+	if err == nil || ctx.Err() != nil || !c.retryPolicy.retryable(attempt, httpResp, err) {
+		return false
+	}
+	return sleep(ctx, c.retryPolicy.backoff(attempt, httpResp))
+}
+
+// sleep waits for d, and returns false if ctx is done before.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,103 @@
+package splunk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"gotest.tools/v3/assert"
+)
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := &RetryPolicy{MaxAttempts: 10, Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, backoff := range expected {
+		assert.Equal(t, p.backoff(i+1, nil), backoff)
+	}
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", "3")
+	assert.Equal(t, p.backoff(1, resp), 3*time.Second)
+	resp.Header.Set("Retry-After", "120")
+	assert.Equal(t, p.backoff(1, resp), 5*time.Second)
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	d, ok := parseRetryAfter("7", now)
+	assert.Assert(t, ok)
+	assert.Equal(t, d, 7*time.Second)
+	d, ok = parseRetryAfter("Wed, 01 Jan 2020 00:00:30 GMT", now)
+	assert.Assert(t, ok)
+	assert.Equal(t, d, 30*time.Second)
+	_, ok = parseRetryAfter("soon", now)
+	assert.Assert(t, !ok)
+	_, ok = parseRetryAfter("", now)
+	assert.Assert(t, !ok)
+}
+
+func TestRetryPolicy_retryable(t *testing.T) {
+	p := &RetryPolicy{MaxAttempts: 2}
+	assert.Assert(t, p.retryable(1, &http.Response{StatusCode: http.StatusServiceUnavailable}, nil))
+	assert.Assert(t, !p.retryable(2, &http.Response{StatusCode: http.StatusServiceUnavailable}, nil))
+	assert.Assert(t, !p.retryable(1, &http.Response{StatusCode: http.StatusInternalServerError}, nil))
+	assert.Assert(t, p.retryable(1, nil, context.DeadlineExceeded))
+	assert.Assert(t, !p.retryable(1, nil, &APIError{Messages: []APIErrorMessage{{Text: "Login failed"}}}))
+
+	p.StatusCodes = []int{http.StatusInternalServerError}
+	assert.Assert(t, p.retryable(1, &http.Response{StatusCode: http.StatusInternalServerError}, nil))
+
+	var nilPolicy *RetryPolicy
+	assert.Assert(t, !nilPolicy.retryable(1, nil, context.DeadlineExceeded))
+}
+
+func TestUserService_CreateRetry(t *testing.T) {
+	var failures, created int32
+	atomic.StoreInt32(&failures, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/services/auth/login":
+			_, _ = w.Write([]byte(`{"sessionKey":"key"}`))
+		case r.URL.Path == "/services/authentication/users" && r.Method == http.MethodPost:
+			// attempts create the user, but fail while there are failures left
+			atomic.AddInt32(&created, 1)
+			if atomic.AddInt32(&failures, -1) >= 0 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"entry":[{"name":"u1"}]}`))
+		case r.URL.Path == "/services/authentication/users/u1" && r.Method == http.MethodGet:
+			if atomic.LoadInt32(&failures) > 0 {
+				// still unavailable
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"entry":[{"name":"u1"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	p := &APIParams{
+		BaseURL: srv.URL,
+		Config:  oauth2.Config{ClientID: "admin", ClientSecret: "secret"},
+		Retry:   &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+	}
+	api := p.NewAPI(context.Background())
+	user, _, err := api.AccessControl.Authentication.Users.Create(context.Background(), &CreateUserOptions{Name: "u1"})
+	assert.NilError(t, err)
+	assert.Equal(t, user.Name, "u1")
+	// the user existed after the second failure, hence no third attempt
+	assert.Equal(t, atomic.LoadInt32(&created), int32(2))
+
+	p.Retry = nil
+	atomic.StoreInt32(&failures, 1)
+	api = p.NewAPI(context.Background())
+	_, _, err = api.AccessControl.Authentication.Users.Create(context.Background(), &CreateUserOptions{Name: "u1"})
+	assert.Assert(t, err != nil)
+}
@@ -0,0 +1,118 @@
+package splunk
+
+import (
+	"context"
+	"net/url"
+)
+
+// RoleService encapsulates the Role portion of the Splunk API.
+type RoleService struct {
+	client *Client
+}
+
+func newRoleService(client *Client) *RoleService {
+	return &RoleService{
+		client: client,
+	}
+}
+
+// RoleEntry is returned from Roles() calls.
+type RoleEntry struct {
+	EntryMetadata
+	Name    string `json:"name"`
+	Content struct {
+		Capabilities               []string `json:"capabilities"`
+		CumulativeRTSrchJobsQuota  int      `json:"cumulativeRTSrchJobsQuota"`
+		CumulativeSrchJobsQuota    int      `json:"cumulativeSrchJobsQuota"`
+		DefaultApp                 string   `json:"defaultApp"`
+		ImportedCapabilities       []string `json:"imported_capabilities"`
+		ImportedRoles              []string `json:"imported_roles"`
+		ImportedSrchFilter         string   `json:"imported_srchFilter"`
+		ImportedSrchIndexesAllowed []string `json:"imported_srchIndexesAllowed"`
+		ImportedSrchIndexesDefault []string `json:"imported_srchIndexesDefault"`
+		RTSrchJobsQuota            int      `json:"rtSrchJobsQuota"`
+		SrchDiskQuota              int      `json:"srchDiskQuota"`
+		SrchFilter                 string   `json:"srchFilter"`
+		SrchIndexesAllowed         []string `json:"srchIndexesAllowed"`
+		SrchIndexesDefault         []string `json:"srchIndexesDefault"`
+		SrchJobsQuota              int      `json:"srchJobsQuota"`
+		SrchTimeWin                int      `json:"srchTimeWin"`
+	} `json:"content"`
+}
+
+var RoleEntryFilterDefault *PaginationFilter
+
+// Roles returns information about all roles matching filter.
+func (s *RoleService) Roles(ctx context.Context, filter *PaginationFilter) ([]RoleEntry, *Response, error) {
+	return s.RolesPager(ctx, filter).All()
+}
+
+// RolesPager returns a Pager over all roles matching filter.
+func (s *RoleService) RolesPager(ctx context.Context, filter *PaginationFilter) *Pager[RoleEntry] {
+	return newPager[RoleEntry](ctx, filter, getPage(s.client, "roles"))
+}
+
+// Role returns information about a single role.
+func (s *RoleService) Role(ctx context.Context, role string) (*RoleEntry, *Response, error) {
+	roles := make([]RoleEntry, 0)
+	resp, err := Receive(ctx, s.client.New().Path("roles/").Get(url.PathEscape(role)), &roles)
+	if err != nil || len(roles) == 0 {
+		return nil, resp, err
+	}
+	return &roles[0], resp, err
+}
+
+// The RoleOptions type provides the attributes of a role, common to creating and updating roles.
+type RoleOptions struct {
+	Capabilities              []string `url:"capabilities,omitempty"`
+	CumulativeRTSrchJobsQuota *int     `url:"cumulativeRTSrchJobsQuota,omitempty"`
+	CumulativeSrchJobsQuota   *int     `url:"cumulativeSrchJobsQuota,omitempty"`
+	DefaultApp                string   `url:"defaultApp,omitempty"`
+	ImportedRoles             []string `url:"imported_roles,omitempty"`
+	RTSrchJobsQuota           *int     `url:"rtSrchJobsQuota,omitempty"`
+	SrchDiskQuota             *int     `url:"srchDiskQuota,omitempty"`
+	SrchFilter                string   `url:"srchFilter,omitempty"`
+	SrchIndexesAllowed        []string `url:"srchIndexesAllowed,omitempty"`
+	SrchIndexesDefault        []string `url:"srchIndexesDefault,omitempty"`
+	SrchJobsQuota             *int     `url:"srchJobsQuota,omitempty"`
+	SrchTimeWin               *int     `url:"srchTimeWin,omitempty"`
+}
+
+// The CreateRoleOptions type provides options for creating a new role.
+type CreateRoleOptions struct {
+	Name string `url:"name"`
+	RoleOptions
+}
+
+// Create creates a new role, and returns additional meta data.
+func (s *RoleService) Create(ctx context.Context, opts *CreateRoleOptions) (*RoleEntry, *Response, error) {
+	roles := make([]RoleEntry, 0)
+	resp, err := Receive(ctx, s.client.New().BodyForm(opts).Post("roles"), &roles)
+	if err != nil || len(roles) == 0 {
+		return nil, resp, err
+	}
+	return &roles[0], resp, err
+}
+
+// The UpdateRoleOptions type provides options for updating a role.
+type UpdateRoleOptions = RoleOptions
+
+// Update updates a role, and returns additional meta data.
+func (s *RoleService) Update(ctx context.Context, role string, opts *UpdateRoleOptions) (*RoleEntry, *Response, error) {
+	roles := make([]RoleEntry, 0)
+	resp, err := Receive(ctx, s.client.New().BodyForm(opts).Path("roles/").Post(url.PathEscape(role)), &roles)
+	if err != nil || len(roles) == 0 {
+		return nil, resp, err
+	}
+	return &roles[0], resp, err
+}
+
+// Delete deletes a role, and returns additional meta data.
+func (s *RoleService) Delete(ctx context.Context, role string) (*RoleEntry, *Response, error) {
+	roles := make([]RoleEntry, 0)
+	resp, err := Receive(ctx, s.client.New().Path("roles/").Delete(url.PathEscape(role)), &roles)
+	if err != nil || len(roles) == 0 {
+		return nil, resp, err
+	}
+	return &roles[0], resp, err
+}
@@ -0,0 +1,84 @@
+package splunk
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRoleService_Create(t *testing.T) {
+	roleSvc := testRoleService(t)
+	params := testRoleParams()
+
+	role, _, err := roleSvc.Create(context.Background(), params)
+	assert.NilError(t, err)
+	// nolint:errcheck
+	defer roleSvc.Delete(context.Background(), role.Name)
+	assert.Equal(t, role.Name, params.Name)
+	assert.DeepEqual(t, role.Content.Capabilities, params.Capabilities)
+	assert.DeepEqual(t, role.Content.ImportedRoles, params.ImportedRoles)
+	assert.DeepEqual(t, role.Content.SrchIndexesAllowed, params.SrchIndexesAllowed)
+	assert.Equal(t, role.Content.SrchFilter, params.SrchFilter)
+
+	role, _, err = roleSvc.Role(context.Background(), params.Name)
+	assert.NilError(t, err)
+	assert.Equal(t, role.Name, params.Name)
+
+	roles, _, err := roleSvc.Roles(context.Background(), RoleEntryFilterDefault)
+	assert.NilError(t, err)
+	found := false
+	for ii := range roles {
+		found = found || roles[ii].Name == params.Name
+	}
+	assert.Assert(t, found)
+}
+
+func TestRoleService_Update(t *testing.T) {
+	roleSvc := testRoleService(t)
+	params := testRoleParams()
+
+	role, _, err := roleSvc.Create(context.Background(), params)
+	assert.NilError(t, err)
+	// nolint:errcheck
+	defer roleSvc.Delete(context.Background(), role.Name)
+
+	role, _, err = roleSvc.Update(context.Background(), role.Name, &UpdateRoleOptions{
+		SrchFilter: "sourcetype=changed",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, role.Content.SrchFilter, "sourcetype=changed")
+}
+
+func TestRoleService_Delete(t *testing.T) {
+	roleSvc := testRoleService(t)
+	params := testRoleParams()
+
+	role, _, err := roleSvc.Create(context.Background(), params)
+	assert.NilError(t, err)
+
+	_, _, err = roleSvc.Delete(context.Background(), role.Name)
+	assert.NilError(t, err)
+
+	_, resp, err := roleSvc.Role(context.Background(), role.Name)
+	assert.ErrorContains(t, err, "")
+	assert.Equal(t, resp.HTTPResponse.StatusCode, 404)
+}
+
+// Helpers
+func testRoleService(t *testing.T) *RoleService {
+	return TestGlobalSplunkClient(t).AccessControl.Authorization.Roles
+}
+
+func testRoleParams() *CreateRoleOptions {
+	return &CreateRoleOptions{
+		Name: testNewUsername("testrole-"),
+		RoleOptions: RoleOptions{
+			Capabilities:       []string{"search"},
+			ImportedRoles:      []string{"user"},
+			SrchIndexesAllowed: []string{"main"},
+			SrchIndexesDefault: []string{"main"},
+			SrchFilter:         "sourcetype=test",
+		},
+	}
+}
@@ -36,6 +36,7 @@ type API struct {
 	AccessControl *AccessControlService
 	Properties    *PropertiesService
 	Deployment    *DeploymentService
+	HEC           *HECService
 	// XXX ...
 }
 
@@ -56,6 +57,7 @@ func (params *APIParams) NewAPI(ctx context.Context) *API {
 		AccessControl: newAccessControlService(client.New()),
 		Properties:    newPropertiesService(client.New()),
 		Deployment:    newDeploymentService(client.New()),
+		HEC:           newHECService(client.New()),
 	}
 }
 
@@ -3,6 +3,7 @@ package splunk
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -86,6 +87,27 @@ func TestGlobalSplunkClient(t *testing.T) *API {
 	return testGlobalSplunkConn
 }
 
+// TestEnableTokenAuth enables authentication with tokens, which is disabled by default in Splunk.
+func TestEnableTokenAuth(t *testing.T, api *API) {
+	t.Helper()
+	opts := struct {
+		Disabled bool `url:"disabled"`
+	}{false}
+	entries := make([]json.RawMessage, 0)
+	if _, err := Receive(context.Background(), api.client.New().BodyForm(&opts).Post("admin/token-auth/tokens_auth"), &entries); err != nil {
+		t.Fatalf("error enabling token authentication: %s", err)
+	}
+}
+
+// TestEnableHEC enables the HTTP Event Collector, which is disabled by default in Splunk.
+func TestEnableHEC(t *testing.T, api *API) {
+	t.Helper()
+	entries := make([]json.RawMessage, 0)
+	if _, err := Receive(context.Background(), api.client.New().Post("data/inputs/http/http/enable"), &entries); err != nil {
+		t.Fatalf("error enabling HTTP Event Collector: %s", err)
+	}
+}
+
 // TestDefaultContext returns a context set up for use in a Splunk client.
 //
 // See also: APIParams.NewAPI
@@ -168,7 +190,7 @@ func NewTestSplunkService() (cleanup func(), conn *API, err error) {
 	// the container seems to take at least one minute to start
 	pool.MaxWait = time.Duration(2) * time.Minute
 	err = pool.Retry(func() error {
-		_, _, err := conn.Introspection.ServerInfo()
+		_, _, err := conn.Introspection.ServerInfo(context.Background())
 		return err
 	})
 	if err != nil {
@@ -189,7 +211,7 @@ func NewTestSplunkServiceWithTempAdmin() (cleanup func(), conn *API, err error)
 	testUserID, _ := uuid.GenerateUUID()
 	testUser := fmt.Sprintf("test-admin-%s", testUserID)
 	testPass, _ := uuid.GenerateUUID()
-	_, _, err = conn.AccessControl.Authentication.Users.Create(&CreateUserOptions{
+	_, _, err = conn.AccessControl.Authentication.Users.Create(context.Background(), &CreateUserOptions{
 		Name:     testUser,
 		Password: testPass,
 		Roles:    []string{"admin"},
@@ -204,7 +226,7 @@ func NewTestSplunkServiceWithTempAdmin() (cleanup func(), conn *API, err error)
 	cleanup = func() {
 		// nolint:errcheck
 		// #nosec G104
-		clConn.AccessControl.Authentication.Users.Delete(testUser)
+		clConn.AccessControl.Authentication.Users.Delete(context.Background(), testUser)
 		clCleanup()
 	}
 	// switch to test (admin) user
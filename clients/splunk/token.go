@@ -0,0 +1,89 @@
+package splunk
+
+import (
+	"context"
+	"net/url"
+)
+
+// TokenService encapsulates the authentication token portion of the Splunk API.
+//
+// Token authentication must be enabled in Splunk, and is available with Splunk 7.3 and later.
+type TokenService struct {
+	client *Client
+}
+
+func newTokenService(client *Client) *TokenService {
+	return &TokenService{
+		client: client,
+	}
+}
+
+// TokenEntry is returned from Tokens() and Create() calls.
+//
+// When listing tokens, Name is the token ID and the token itself is not returned.  When creating
+// a token, ID and Token are set.
+type TokenEntry struct {
+	EntryMetadata
+	Name    string `json:"name"`
+	Content struct {
+		Claims struct {
+			Audience  string `json:"aud"`
+			ExpiresOn int64  `json:"exp"`
+			IssuedAt  int64  `json:"iat"`
+			Issuer    string `json:"iss"`
+			NotBefore int64  `json:"nbr"`
+			Subject   string `json:"sub"`
+		} `json:"claims"`
+		ID         string `json:"id"`
+		LastUsed   int64  `json:"lastUsed"`
+		LastUsedIP string `json:"lastUsedIp"`
+		Status     string `json:"status"`
+		Token      string `json:"token"`
+	} `json:"content"`
+}
+
+var TokenEntryFilterDefault *PaginationFilter
+
+// Tokens returns information about all tokens matching filter.
+func (s *TokenService) Tokens(ctx context.Context, filter *PaginationFilter) ([]TokenEntry, *Response, error) {
+	return s.TokensPager(ctx, filter).All()
+}
+
+// TokensPager returns a Pager over all tokens matching filter.
+func (s *TokenService) TokensPager(ctx context.Context, filter *PaginationFilter) *Pager[TokenEntry] {
+	return newPager[TokenEntry](ctx, filter, getPage(s.client, "tokens"))
+}
+
+// The CreateTokenOptions type provides options for creating a new token.
+type CreateTokenOptions struct {
+	// Name is the user the token is issued for.
+	Name     string `url:"name"`
+	Audience string `url:"audience"`
+	// ExpiresOn and NotBefore take absolute times, like "2020-01-01T00:00:00", or relative ones,
+	// like "+1h".
+	ExpiresOn string `url:"expires_on,omitempty"`
+	NotBefore string `url:"not_before,omitempty"`
+	// Type is either "static", the default, or "ephemeral".
+	Type string `url:"type,omitempty"`
+}
+
+// Create creates a new token, and returns additional meta data.
+func (s *TokenService) Create(ctx context.Context, opts *CreateTokenOptions) (*TokenEntry, *Response, error) {
+	tokens := make([]TokenEntry, 0)
+	resp, err := Receive(ctx, s.client.New().BodyForm(opts).Post("tokens"), &tokens)
+	if err != nil || len(tokens) == 0 {
+		return nil, resp, err
+	}
+	return &tokens[0], resp, err
+}
+
+type deleteTokenOptions struct {
+	ID string `url:"id"`
+}
+
+// Delete deletes the token with the given ID issued for user, and returns additional meta data.
+func (s *TokenService) Delete(ctx context.Context, user, id string) (*Response, error) {
+	tokens := make([]TokenEntry, 0)
+	sling := s.client.New().Path("tokens/").Delete(url.PathEscape(user)).QueryStruct(&deleteTokenOptions{id})
+	return Receive(ctx, sling, &tokens)
+}
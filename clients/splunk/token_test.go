@@ -0,0 +1,64 @@
+package splunk
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestTokenService_Create(t *testing.T) {
+	tokenSvc := testTokenService(t)
+	username := testGlobalSplunkConn.Params().ClientID
+
+	token, _, err := tokenSvc.Create(context.Background(), &CreateTokenOptions{
+		Name:      username,
+		Audience:  "test",
+		ExpiresOn: "+1h",
+	})
+	assert.NilError(t, err)
+	// nolint:errcheck
+	defer tokenSvc.Delete(context.Background(), username, token.Content.ID)
+	assert.Assert(t, token.Content.ID != "")
+	assert.Assert(t, token.Content.Token != "")
+
+	tokens, _, err := tokenSvc.Tokens(context.Background(), TokenEntryFilterDefault)
+	assert.NilError(t, err)
+	found := false
+	for ii := range tokens {
+		if tokens[ii].Name == token.Content.ID {
+			found = true
+			assert.Equal(t, tokens[ii].Content.Claims.Subject, username)
+			assert.Equal(t, tokens[ii].Content.Claims.Audience, "test")
+		}
+	}
+	assert.Assert(t, found)
+}
+
+func TestTokenService_Delete(t *testing.T) {
+	tokenSvc := testTokenService(t)
+	username := testGlobalSplunkConn.Params().ClientID
+
+	token, _, err := tokenSvc.Create(context.Background(), &CreateTokenOptions{
+		Name:      username,
+		Audience:  "test",
+		ExpiresOn: "+1h",
+	})
+	assert.NilError(t, err)
+
+	_, err = tokenSvc.Delete(context.Background(), username, token.Content.ID)
+	assert.NilError(t, err)
+
+	tokens, _, err := tokenSvc.Tokens(context.Background(), TokenEntryFilterDefault)
+	assert.NilError(t, err)
+	for ii := range tokens {
+		assert.Assert(t, tokens[ii].Name != token.Content.ID)
+	}
+}
+
+// Helpers
+func testTokenService(t *testing.T) *TokenService {
+	api := TestGlobalSplunkClient(t)
+	TestEnableTokenAuth(t, api)
+	return api.AccessControl.Authorization.Tokens
+}
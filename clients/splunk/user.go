@@ -1,6 +1,8 @@
 package splunk
 
 import (
+	"context"
+	"fmt"
 	"net/url"
 )
 
@@ -33,11 +35,23 @@ type UserEntry struct {
 	} `json:"content"`
 }
 
-// Users returns information about all users.
-func (s *UserService) Users() ([]UserEntry, *Response, error) {
-	users := make([]UserEntry, 0)
-	resp, err := Receive(s.client.New().Get("users"), &users)
-	return users, resp, err
+var UserEntryFilterDefault *PaginationFilter
+
+// UserEntryFilterPrefix returns a filter for users whose names start with prefix.
+func UserEntryFilterPrefix(prefix string) *PaginationFilter {
+	return &PaginationFilter{
+		Search: fmt.Sprintf("name=%s*", prefix),
+	}
+}
+
+// Users returns information about all users matching filter.
+func (s *UserService) Users(ctx context.Context, filter *PaginationFilter) ([]UserEntry, *Response, error) {
+	return s.UsersPager(ctx, filter).All()
+}
+
+// UsersPager returns a Pager over all users matching filter.
+func (s *UserService) UsersPager(ctx context.Context, filter *PaginationFilter) *Pager[UserEntry] {
+	return newPager[UserEntry](ctx, filter, getPage(s.client, "users"))
 }
 
 // The CreateUserOptions type provides options for creating a new user.
@@ -54,16 +68,38 @@ type CreateUserOptions struct {
 	TZ                    string   `url:"tz,omitempty"`
 }
 
-// Create creates a new user, and returns additional meta data.
-func (s *UserService) Create(opts *CreateUserOptions) (*UserEntry, *Response, error) {
+// User returns information about user.
+func (s *UserService) User(ctx context.Context, user string) (*UserEntry, *Response, error) {
 	users := make([]UserEntry, 0)
-	resp, err := Receive(s.client.New().BodyForm(opts).Post("users"), &users)
+	resp, err := Receive(ctx, s.client.New().Path("users/").Get(url.PathEscape(user)), &users)
 	if err != nil || len(users) == 0 {
 		return nil, resp, err
 	}
 	return &users[0], resp, err
 }
 
+// Create creates a new user, and returns additional meta data.
+//
+// Creating a user is not idempotent, hence a failed attempt is only retried if the user does not exist.
+// Users must have unique names for this to be safe.
+func (s *UserService) Create(ctx context.Context, opts *CreateUserOptions) (*UserEntry, *Response, error) {
+	for attempt := 1; ; attempt++ {
+		users := make([]UserEntry, 0)
+		resp, err := Receive(ctx, s.client.New().BodyForm(opts).Post("users"), &users)
+		if err != nil && s.client.retry(ctx, attempt, resp, err) {
+			if user, _, userErr := s.User(ctx, opts.Name); userErr == nil && user != nil {
+				// the failed attempt created the user after all
+				return user, resp, nil
+			}
+			continue
+		}
+		if err != nil || len(users) == 0 {
+			return nil, resp, err
+		}
+		return &users[0], resp, err
+	}
+}
+
 // The UpdateUserOptions type provides options for updating a user.
 type UpdateUserOptions struct {
 	DefaultApp            string   `url:"defaultApp,omitempty"`
@@ -78,9 +114,9 @@ type UpdateUserOptions struct {
 }
 
 // Update updates a user, and returns additional meta data.
-func (s *UserService) Update(user string, opts *UpdateUserOptions) (*UserEntry, *Response, error) {
+func (s *UserService) Update(ctx context.Context, user string, opts *UpdateUserOptions) (*UserEntry, *Response, error) {
 	users := make([]UserEntry, 0)
-	resp, err := Receive(s.client.New().BodyForm(opts).Path("users/").Post(url.PathEscape(user)), &users)
+	resp, err := Receive(ctx, s.client.New().BodyForm(opts).Path("users/").Post(url.PathEscape(user)), &users)
 	if err != nil || len(users) == 0 {
 		return nil, resp, err
 	}
@@ -88,9 +124,9 @@ func (s *UserService) Update(user string, opts *UpdateUserOptions) (*UserEntry,
 }
 
 // Delete deletes a user, and returns additional meta data.
-func (s *UserService) Delete(user string) (*UserEntry, *Response, error) {
+func (s *UserService) Delete(ctx context.Context, user string) (*UserEntry, *Response, error) {
 	users := make([]UserEntry, 0)
-	resp, err := Receive(s.client.New().Path("users/").Delete(url.PathEscape(user)), &users)
+	resp, err := Receive(ctx, s.client.New().Path("users/").Delete(url.PathEscape(user)), &users)
 	if err != nil || len(users) == 0 {
 		return nil, resp, err
 	}
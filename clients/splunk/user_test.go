@@ -1,8 +1,10 @@
 package splunk
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/go-uuid"
@@ -15,7 +17,7 @@ const defaultAdminUser = "admin"
 func TestUserService_Users(t *testing.T) {
 	us := testUserService(t)
 
-	users, _, err := us.Users()
+	users, _, err := us.Users(context.Background(), UserEntryFilterDefault)
 	assert.NilError(t, err)
 	for ii := range users {
 		if users[ii].Name == defaultAdminUser {
@@ -25,14 +27,34 @@ func TestUserService_Users(t *testing.T) {
 	t.Fail()
 }
 
+func TestUserService_Users_Prefix(t *testing.T) {
+	userSvc := testUserService(t)
+	params := testUserParams(testNewUsername("testprefix-"))
+
+	user, _, err := userSvc.Create(context.Background(), params)
+	assert.NilError(t, err)
+	// nolint:errcheck
+	defer userSvc.Delete(context.Background(), user.Name)
+
+	users, _, err := userSvc.Users(context.Background(), UserEntryFilterPrefix("testprefix-"))
+	assert.NilError(t, err)
+	assert.Assert(t, len(users) > 0)
+	found := false
+	for ii := range users {
+		assert.Assert(t, strings.HasPrefix(users[ii].Name, "testprefix-"))
+		found = found || users[ii].Name == user.Name
+	}
+	assert.Assert(t, found)
+}
+
 func TestUserService_Create(t *testing.T) {
 	userSvc := testUserService(t)
 	params := testUserParams("")
 
-	user, _, err := userSvc.Create(params)
+	user, _, err := userSvc.Create(context.Background(), params)
 	assert.NilError(t, err)
 	// nolint:errcheck
-	defer userSvc.Delete(user.Name)
+	defer userSvc.Delete(context.Background(), user.Name)
 	assert.Equal(t, user.Name, params.Name)
 	assert.Equal(t, user.Content.Email, params.Email)
 }
@@ -41,10 +63,10 @@ func TestUserService_Delete(t *testing.T) {
 	userSvc := testUserService(t)
 	params := testUserParams("")
 
-	user, _, err := userSvc.Create(params)
+	user, _, err := userSvc.Create(context.Background(), params)
 	assert.NilError(t, err)
 
-	_, _, err = userSvc.Delete(user.Name)
+	_, _, err = userSvc.Delete(context.Background(), user.Name)
 	assert.NilError(t, err)
 }
 
@@ -52,13 +74,13 @@ func TestUserService_Update_Email(t *testing.T) {
 	userSvc := testUserService(t)
 	params := testUserParams("")
 
-	user, _, err := userSvc.Create(params)
+	user, _, err := userSvc.Create(context.Background(), params)
 	assert.NilError(t, err)
 	// nolint:errcheck
-	defer userSvc.Delete(user.Name)
+	defer userSvc.Delete(context.Background(), user.Name)
 	assert.Equal(t, user.Name, params.Name)
 
-	user, _, err = userSvc.Update(user.Name, &UpdateUserOptions{
+	user, _, err = userSvc.Update(context.Background(), user.Name, &UpdateUserOptions{
 		Email: "changed@example.com",
 	})
 	assert.NilError(t, err)
@@ -69,14 +91,14 @@ func TestUserService_Update_Password(t *testing.T) {
 	userSvc := testUserService(t)
 	params := testUserParams("")
 
-	user, _, err := userSvc.Create(params)
+	user, _, err := userSvc.Create(context.Background(), params)
 	assert.NilError(t, err)
 	// nolint:errcheck
-	defer userSvc.Delete(user.Name)
+	defer userSvc.Delete(context.Background(), user.Name)
 	assert.NilError(t, err)
 	assert.Equal(t, user.Name, params.Name)
 
-	_, _, err = userSvc.Update(user.Name, &UpdateUserOptions{
+	_, _, err = userSvc.Update(context.Background(), user.Name, &UpdateUserOptions{
 		Password: "changed1234",
 	})
 	assert.NilError(t, err)
@@ -86,7 +108,7 @@ func TestUserService_Update_MissingOldPassword(t *testing.T) {
 	userSvc := testUserService(t)
 	self := testGlobalSplunkConn.Params().ClientID
 
-	_, _, err := userSvc.Update(self, &UpdateUserOptions{
+	_, _, err := userSvc.Update(context.Background(), self, &UpdateUserOptions{
 		Password: "changed1234",
 	})
 	assert.Error(t, err, "ERROR splunk: Missing old password.")
@@ -96,12 +118,12 @@ func TestUserService_Update_OwnPassword(t *testing.T) {
 	userSvc := testUserService(t)
 
 	params := testUserParams("")
-	user, _, err := userSvc.Create(params)
+	user, _, err := userSvc.Create(context.Background(), params)
 	assert.NilError(t, err)
 	// nolint:errcheck
-	defer userSvc.Delete(user.Name)
+	defer userSvc.Delete(context.Background(), user.Name)
 
-	_, _, err = userSvc.Update(user.Name, &UpdateUserOptions{
+	_, _, err = userSvc.Update(context.Background(), user.Name, &UpdateUserOptions{
 		OldPassword: params.Password,
 		Password:    "password",
 	})
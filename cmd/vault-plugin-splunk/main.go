@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	metrics "github.com/armon/go-metrics"
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/sdk/plugin"
@@ -23,6 +24,7 @@ func main() {
 	apiClientMeta := &api.PluginAPIClientMeta{}
 	flags := apiClientMeta.FlagSet()
 	printVersion := flags.Bool("version", false, "Prints version")
+	statsdAddr := flags.String("statsd-address", "", "Reports telemetry to this statsd server")
 
 	// all plugins ignore Parse errors
 	// #nosec G104
@@ -43,6 +45,12 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *statsdAddr != "" {
+		if err := setupTelemetry(*statsdAddr); err != nil {
+			hclog.New(&hclog.LoggerOptions{}).Error("error setting up telemetry", "error", err)
+		}
+	}
+
 	tlsConfig := apiClientMeta.GetTLSConfig()
 	tlsProviderFunc := api.VaultPluginTLSProvider(tlsConfig)
 
@@ -57,3 +65,18 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// setupTelemetry reports metrics to a statsd server, under the same "vault" prefix as Vault's own metrics.
+//
+// Plugins run in a separate process, hence they cannot use the telemetry configuration of Vault.
+func setupTelemetry(statsdAddr string) error {
+	sink, err := metrics.NewStatsdSink(statsdAddr)
+	if err != nil {
+		return err
+	}
+	config := metrics.DefaultConfig("vault")
+	// these would clash with the runtime metrics of Vault itself
+	config.EnableRuntimeMetrics = false
+	_, err = metrics.NewGlobal(config, sink)
+	return err
+}
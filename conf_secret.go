@@ -0,0 +1,125 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fatih/structs"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const confSecretsPrefix = "conf-secrets/"
+
+// confSecretConfig manages a secret in a Splunk configuration file, like pass4SymmKey in server.conf.
+type confSecretConfig struct {
+	Connection     string        `json:"connection" structs:"connection"`
+	File           string        `json:"file" structs:"file"`
+	Stanza         string        `json:"stanza" structs:"stanza"`
+	Key            string        `json:"key" structs:"key"`
+	NodeFQDNs      []string      `json:"node_fqdns" structs:"node_fqdns"`
+	RotationPeriod time.Duration `json:"rotation_period" structs:"rotation_period"`
+	PasswordSpec   *PasswordSpec `json:"password_spec" structs:"password_spec"`
+
+	// managed by the plugin
+	Value       string    `json:"value" structs:"-"`
+	LastRotated time.Time `json:"last_vault_rotation" structs:"last_vault_rotation,omitnested"`
+}
+
+// confSecretConfigLoad returns nil if the conf secret named `name` does not exist in `storage`, otherwise
+// returns the conf secret.  The second return value is non-nil on error.
+func confSecretConfigLoad(ctx context.Context, s logical.Storage, name string) (*confSecretConfig, error) {
+	if name == "" {
+		return nil, fmt.Errorf("invalid conf secret name")
+	}
+
+	entry, err := s.Get(ctx, confSecretsPrefix+name)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving conf secret: %w", err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	secret := confSecretConfig{}
+	if err := entry.DecodeJSON(&secret); err != nil {
+		return nil, fmt.Errorf("error decoding conf secret: %w", err)
+	}
+	return &secret, nil
+}
+
+func (secret *confSecretConfig) store(ctx context.Context, s logical.Storage, name string) error {
+	entry, err := logical.StorageEntryJSON(confSecretsPrefix+name, secret)
+	if err != nil {
+		return err
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return fmt.Errorf("error writing %q JSON: %w", confSecretsPrefix+name, err)
+	}
+	return nil
+}
+
+// confSecretSecrets are the values of conf secrets.
+var confSecretSecrets = &rotatedSecretKind{
+	name:    "conf secret",
+	prefix:  confSecretsPrefix,
+	walType: walTypeConfSecret,
+	lock:    func(b *backend) *sync.Mutex { return &b.confSecretLock },
+	load: func(ctx context.Context, s logical.Storage, name string) (rotatedSecret, error) {
+		secret, err := confSecretConfigLoad(ctx, s, name)
+		if secret == nil {
+			return nil, err
+		}
+		return secret, err
+	},
+	new: func() rotatedSecret { return &confSecretConfig{} },
+}
+
+// NextRotation returns the time of the next scheduled rotation.
+func (secret *confSecretConfig) NextRotation() time.Time {
+	return secret.LastRotated.Add(secret.RotationPeriod)
+}
+
+func (secret *confSecretConfig) connectionName() string {
+	return secret.Connection
+}
+
+func (secret *confSecretConfig) passwordSpec() *PasswordSpec {
+	return secret.PasswordSpec
+}
+
+func (secret *confSecretConfig) current() string {
+	return secret.Value
+}
+
+func (secret *confSecretConfig) lastRotated() time.Time {
+	return secret.LastRotated
+}
+
+func (secret *confSecretConfig) rotated(value string, now time.Time) {
+	secret.Value = value
+	secret.LastRotated = now
+}
+
+func (secret *confSecretConfig) sameTarget(other rotatedSecret) bool {
+	otherSecret, ok := other.(*confSecretConfig)
+	return ok && secret.sameLocation(otherSecret)
+}
+
+// nodes returns the nodes to write the secret to.  The empty string denotes the node of the connection URL.
+func (secret *confSecretConfig) nodes() []string {
+	if len(secret.NodeFQDNs) == 0 {
+		return []string{""}
+	}
+	return secret.NodeFQDNs
+}
+
+func (secret *confSecretConfig) toResponseData() map[string]interface{} {
+	data := structs.New(secret).Map()
+	data["rotation_period"] = int64(secret.RotationPeriod.Seconds())
+	data["next_vault_rotation"] = secret.NextRotation()
+	delete(data, "password_spec")
+	addPasswordSpecResponseData(data, secret.PasswordSpec)
+	return data
+}
@@ -6,12 +6,16 @@ import (
 	"crypto/x509"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/fatih/structs"
 	uuid "github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/helper/tlsutil"
 	"github.com/hashicorp/vault/sdk/helper/useragent"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -22,13 +26,23 @@ import (
 
 const (
 	respErrEmptyName = `missing or empty "name" parameter`
+
+	defaultNodeURLTemplate = "https://{{.Host}}:8089"
+
+	defaultRetryMaxAttempts = 3
 )
 
+// requiredCapabilities are the Splunk capabilities the admin user needs for managing users.
+var requiredCapabilities = []string{"edit_user"}
+
 type splunkConfig struct {
 	ID             string        `json:"id" structs:"id"`
 	Username       string        `json:"username" structs:"username"`
 	Password       string        `json:"password" structs:"password"`
+	Token          string        `json:"token,omitempty" structs:"token"`
+	TokenID        string        `json:"token_id,omitempty" structs:"token_id"`
 	URL            string        `json:"url" structs:"url"`
+	FailoverURLs   []string      `json:"failover_urls" structs:"failover_urls"`
 	IsStandalone   bool          `json:"is_standalone" structs:"is_standalone"`
 	AllowedRoles   []string      `json:"allowed_roles" structs:"allowed_roles"`
 	Verify         bool          `json:"verify" structs:"verify"`
@@ -39,16 +53,92 @@ type splunkConfig struct {
 	RootCA         []string      `json:"root_ca" structs:"root_ca"`
 	TLSMinVersion  string        `json:"tls_min_version" structs:"tls_min_version"`
 	ConnectTimeout time.Duration `json:"connect_timeout" structs:"connect_timeout"`
+
+	RetryMaxAttempts int           `json:"retry_max_attempts" structs:"retry_max_attempts"`
+	RetryBackoff     time.Duration `json:"retry_backoff" structs:"retry_backoff"`
+	RetryMaxBackoff  time.Duration `json:"retry_max_backoff" structs:"retry_max_backoff"`
+	RetryStatusCodes []int         `json:"retry_status_codes" structs:"retry_status_codes"`
+
+	RotationPeriod time.Duration `json:"rotation_period" structs:"rotation_period"`
+	RotationWindow time.Duration `json:"rotation_window" structs:"rotation_window"`
+
+	TidyPeriod       time.Duration `json:"tidy_period" structs:"tidy_period"`
+	TidySafetyBuffer time.Duration `json:"tidy_safety_buffer" structs:"tidy_safety_buffer"`
+
+	NodeDiscovery string `json:"node_discovery" structs:"node_discovery"`
+	// NodeURLTemplate is a text/template for node URLs, executed with a discoveredNode
+	NodeURLTemplate string `json:"node_url_template" structs:"node_url_template"`
+	// Nodes is the static node inventory
+	Nodes []staticNode `json:"nodes,omitempty" structs:"-"`
+
+	SHCCaptain            bool          `json:"shc_captain" structs:"shc_captain"`
+	SHCReplicationTimeout time.Duration `json:"shc_replication_timeout" structs:"shc_replication_timeout"`
+
+	// name of the connection, not stored
+	name string
 }
 
 func (config *splunkConfig) toResponseData() map[string]interface{} {
 	data := structs.New(config).Map()
 	data["connect_timeout"] = int64(config.ConnectTimeout.Seconds())
+	data["retry_max_attempts"] = config.retryMaxAttempts()
+	data["retry_backoff"] = int64(config.RetryBackoff.Seconds())
+	data["retry_max_backoff"] = int64(config.RetryMaxBackoff.Seconds())
+	data["rotation_period"] = int64(config.RotationPeriod.Seconds())
+	data["rotation_window"] = int64(config.RotationWindow.Seconds())
+	data["shc_replication_timeout"] = int64(config.SHCReplicationTimeout.Seconds())
+	data["tidy_period"] = int64(config.TidyPeriod.Seconds())
+	data["tidy_safety_buffer"] = int64(config.tidySafetyBuffer().Seconds())
+	data["node_discovery"] = config.nodeDiscovery()
+	data["node_url_template"] = config.nodeURLTemplate()
+	nodes := make([]map[string]interface{}, 0, len(config.Nodes))
+	for i := range config.Nodes {
+		nodes = append(nodes, config.Nodes[i].toResponseData())
+	}
+	data["nodes"] = nodes
 	data["password"] = "n/a"
 	data["private_key"] = "n/a"
+	data["token"] = "n/a"
 	return data
 }
 
+// tidySafetyBuffer returns the effective safety buffer for tidying orphaned users.
+func (config *splunkConfig) tidySafetyBuffer() time.Duration {
+	if config.TidySafetyBuffer == 0 {
+		return defaultTidySafetyBuffer
+	}
+	return config.TidySafetyBuffer
+}
+
+// nodeURLTemplate returns the effective template for node URLs.
+func (config *splunkConfig) nodeURLTemplate() string {
+	if config.NodeURLTemplate == "" {
+		return defaultNodeURLTemplate
+	}
+	return config.NodeURLTemplate
+}
+
+// nodeURL returns the URL for connecting to node.
+func (config *splunkConfig) nodeURL(node *discoveredNode) (string, error) {
+	tmpl, err := template.New("node_url_template").Parse(config.nodeURLTemplate())
+	if err != nil {
+		return "", fmt.Errorf("invalid node_url_template: %w", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, node); err != nil {
+		return "", fmt.Errorf("invalid node_url_template: %w", err)
+	}
+	nodeURL := b.String()
+	u, err := url.Parse(nodeURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid node URL %q: %w", nodeURL, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("invalid node URL %q: expected http(s)://host[:port]", nodeURL)
+	}
+	return nodeURL, nil
+}
+
 func (config *splunkConfig) toMinimalResponseData() map[string]interface{} {
 	data := map[string]interface{}{
 		"id":       config.ID,
@@ -98,13 +188,76 @@ func (config *splunkConfig) store(ctx context.Context, s logical.Storage, name s
 		return fmt.Errorf("error saving new config/%s: %w", name, err)
 	}
 
-	// if config.Verify {
-	// 	 config.verifyConnection(ctx, s, name)
-	// }
+	return err
+}
 
+// checkLogin establishes a new session with the configured credentials.
+func (config *splunkConfig) checkLogin(ctx context.Context) error {
+	conn, err := config.newConnection(ctx)
+	if err != nil {
+		return err
+	}
+	// any request triggers a login
+	_, _, err = conn.Introspection.ServerInfo(ctx)
 	return err
 }
 
+// verifyConnection checks that the connection details are usable by connecting to Splunk,
+// and making sure that the admin user can manage users.  Failover URLs are verified as well.
+func (config *splunkConfig) verifyConnection(ctx context.Context) error {
+	for _, u := range append([]string{config.URL}, config.FailoverURLs...) {
+		endpointConfig := *config
+		endpointConfig.URL = u
+		endpointConfig.FailoverURLs = nil
+		if err := endpointConfig.verifyEndpoint(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (config *splunkConfig) verifyEndpoint(ctx context.Context) error {
+	conn, err := config.newConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	if config.Token == "" {
+		if _, err := conn.AccessControl.Authentication.Login(ctx, config.Username, config.Password); err != nil {
+			return fmt.Errorf("unable to log in to %s as %q: %w", config.URL, config.Username, err)
+		}
+	}
+	if _, _, err := conn.Introspection.ServerInfo(ctx); err != nil {
+		return fmt.Errorf("unable to read server info from %s: %w", config.URL, err)
+	}
+
+	// with token authentication, the user is only known from the current context
+	user := fmt.Sprintf("%q", config.Username)
+	if config.Token != "" {
+		user = "the token's owner"
+	}
+	userContext, _, err := conn.AccessControl.Authentication.CurrentContext(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to read capabilities of %s: %w", user, err)
+	}
+	if userContext == nil {
+		return fmt.Errorf("unable to read capabilities of %s: empty response", user)
+	}
+	if userContext.Content.Username != "" {
+		user = fmt.Sprintf("%q", userContext.Content.Username)
+	}
+	var missing []string
+	for _, capability := range requiredCapabilities {
+		if !strutil.StrListContains(userContext.Content.Capabilities, capability) {
+			missing = append(missing, capability)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("user %s is missing required capabilities: %q", user, missing)
+	}
+	return nil
+}
+
 func connectionConfigExists(ctx context.Context, s logical.Storage, name string) (bool, error) {
 	if name == "" {
 		return false, fmt.Errorf(respErrEmptyName)
@@ -129,7 +282,7 @@ func connectionConfigLoad(ctx context.Context, s logical.Storage, name string) (
 		return nil, fmt.Errorf("connection configuration not found: %q", name)
 	}
 
-	config := splunkConfig{}
+	config := splunkConfig{name: name}
 	if err := entry.DecodeJSON(&config); err != nil {
 		return nil, err
 	}
@@ -138,8 +291,11 @@ func connectionConfigLoad(ctx context.Context, s logical.Storage, name string) (
 
 func (config *splunkConfig) newConnection(ctx context.Context) (*splunk.API, error) {
 	p := &splunk.APIParams{
-		BaseURL:   config.URL,
-		UserAgent: useragent.String(),
+		BaseURL:      config.URL,
+		FailoverURLs: config.FailoverURLs,
+		BearerToken:  config.Token,
+		Retry:        config.retryPolicy(),
+		UserAgent:    useragent.String(),
 		Config: oauth2.Config{
 			ClientID:     config.Username,
 			ClientSecret: config.Password,
@@ -166,6 +322,28 @@ func (config *splunkConfig) newConnection(ctx context.Context) (*splunk.API, err
 	return p.NewAPI(ctx), nil
 }
 
+// retryMaxAttempts returns the effective maximum number of attempts for API calls.  Connections stored
+// before retries were configurable have 0.
+func (config *splunkConfig) retryMaxAttempts() int {
+	if config.RetryMaxAttempts == 0 {
+		return defaultRetryMaxAttempts
+	}
+	return config.RetryMaxAttempts
+}
+
+// retryPolicy returns the policy for retrying failed API calls, or nil if retries are disabled.
+func (config *splunkConfig) retryPolicy() *splunk.RetryPolicy {
+	if config.retryMaxAttempts() <= 1 {
+		return nil
+	}
+	return &splunk.RetryPolicy{
+		MaxAttempts: config.retryMaxAttempts(),
+		Backoff:     config.RetryBackoff,
+		MaxBackoff:  config.RetryMaxBackoff,
+		StatusCodes: config.RetryStatusCodes,
+	}
+}
+
 func (config *splunkConfig) tlsConfig() (tlsConfig *tls.Config, err error) {
 	if len(config.Certificate) > 0 || (config.CAChain != nil && len(config.CAChain) > 0) {
 		if len(config.Certificate) > 0 && len(config.PrivateKey) == 0 {
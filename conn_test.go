@@ -0,0 +1,67 @@
+package splunk
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSplunkConfig_nodeURL(t *testing.T) {
+	node := &discoveredNode{Host: "10.0.0.1", HostFQDN: "idx1.example.com", ServerName: "idx1", GUID: "G1", Site: "site2"}
+
+	config := &splunkConfig{}
+	u, err := config.nodeURL(node)
+	assert.NilError(t, err)
+	assert.Equal(t, u, "https://10.0.0.1:8089")
+
+	config.NodeURLTemplate = "https://{{.HostFQDN}}:18089"
+	u, err = config.nodeURL(node)
+	assert.NilError(t, err)
+	assert.Equal(t, u, "https://idx1.example.com:18089")
+
+	config.NodeURLTemplate = "https://proxy-{{.Site}}.example.com/{{.GUID}}"
+	u, err = config.nodeURL(node)
+	assert.NilError(t, err)
+	assert.Equal(t, u, "https://proxy-site2.example.com/G1")
+
+	config.NodeURLTemplate = "https://{{.Host"
+	_, err = config.nodeURL(node)
+	assert.ErrorContains(t, err, "invalid node_url_template")
+
+	config.NodeURLTemplate = "https://{{.Unknown}}"
+	_, err = config.nodeURL(node)
+	assert.ErrorContains(t, err, "invalid node_url_template")
+
+	config.NodeURLTemplate = "{{.Host}}:8089"
+	_, err = config.nodeURL(node)
+	assert.ErrorContains(t, err, "invalid node URL")
+}
+
+func TestSplunkConfig_retryPolicy(t *testing.T) {
+	// connections stored before retries were configurable
+	config := &splunkConfig{}
+	assert.Equal(t, config.retryPolicy().MaxAttempts, defaultRetryMaxAttempts)
+	assert.Equal(t, config.toResponseData()["retry_max_attempts"], defaultRetryMaxAttempts)
+
+	config.RetryMaxAttempts = 1
+	assert.Assert(t, config.retryPolicy() == nil)
+
+	config.RetryMaxAttempts = 5
+	assert.Equal(t, config.retryPolicy().MaxAttempts, 5)
+}
+
+func TestSplunkConfig_verifyEndpointToken(t *testing.T) {
+	node := testNewFakeNode(t)
+	node.setCurrentContext("token_owner")
+	config := &splunkConfig{
+		URL:      node.URL,
+		Username: "admin",
+		Token:    "token",
+	}
+	err := config.verifyEndpoint(context.Background())
+	assert.Error(t, err, `user "token_owner" is missing required capabilities: ["edit_user"]`)
+
+	node.setCurrentContext("token_owner", "edit_user")
+	assert.NilError(t, config.verifyEndpoint(context.Background()))
+}
@@ -0,0 +1,225 @@
+package splunk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/v3/assert"
+)
+
+// testFakeNode serves just enough of the Splunk API for verifying connections, managing users and conf
+// secrets on a node, and for looking up search head cluster members.
+type testFakeNode struct {
+	*httptest.Server
+
+	mu sync.Mutex
+	// API calls with these HTTP methods fail; logging in always succeeds
+	failMethods map[string]bool
+	// delay is added to all API calls but logging in
+	delay      time.Duration
+	properties map[string]string
+	users      map[string]bool
+	passwords  map[string]string
+	// user and capabilities of the current context
+	currentUser  string
+	capabilities []string
+	// entries served by the search head cluster endpoints, if not nil
+	shcCaptain map[string]interface{}
+	shcMembers []map[string]interface{}
+}
+
+func testNewFakeNode(t *testing.T) *testFakeNode {
+	node := &testFakeNode{
+		failMethods: make(map[string]bool),
+		properties:  make(map[string]string),
+		users:       make(map[string]bool),
+		passwords:   make(map[string]string),
+	}
+	node.Server = httptest.NewServer(http.HandlerFunc(node.serveHTTP))
+	t.Cleanup(node.Close)
+	return node
+}
+
+func (node *testFakeNode) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	if r.URL.Path == "/services/auth/login" {
+		_, _ = w.Write([]byte(`{"sessionKey":"fake"}`))
+		return
+	}
+	time.Sleep(node.delay)
+	if node.failMethods[r.Method] {
+		writeFakeError(w, http.StatusBadRequest, "injected failure")
+		return
+	}
+	// the client does not set a form content type
+	body, _ := ioutil.ReadAll(r.Body)
+	form, _ := url.ParseQuery(string(body))
+
+	path := strings.TrimPrefix(r.URL.Path, "/services/")
+	switch {
+	case strings.HasPrefix(path, "properties/") && r.Method == http.MethodPost:
+		node.properties[strings.TrimPrefix(path, "properties/")] = form.Get("value")
+	case path == "authentication/users" && r.Method == http.MethodGet:
+		var names []string
+		for name := range node.users {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		entries := make([]map[string]interface{}, 0, len(names))
+		for _, name := range names {
+			entries = append(entries, map[string]interface{}{"name": name})
+		}
+		writeFakeEntries(w, entries)
+	case path == "authentication/users" && r.Method == http.MethodPost:
+		name := form.Get("name")
+		if node.users[name] {
+			writeFakeError(w, http.StatusBadRequest, "user exists")
+			return
+		}
+		node.users[name] = true
+		node.passwords[name] = form.Get("password")
+		writeFakeEntries(w, []map[string]interface{}{{"name": name}})
+	case strings.HasPrefix(path, "authentication/users/"):
+		name := strings.TrimPrefix(path, "authentication/users/")
+		if !node.users[name] {
+			writeFakeError(w, http.StatusNotFound, "not found")
+			return
+		}
+		switch r.Method {
+		case http.MethodPost:
+			if password := form.Get("password"); password != "" {
+				node.passwords[name] = password
+			}
+		case http.MethodDelete:
+			delete(node.users, name)
+			delete(node.passwords, name)
+		}
+		writeFakeEntries(w, []map[string]interface{}{{"name": name}})
+	case path == "server/info":
+		writeFakeEntries(w, []map[string]interface{}{{"name": "server-info"}})
+	case path == "authentication/current-context":
+		writeFakeEntries(w, []map[string]interface{}{{
+			"name":    "context",
+			"content": map[string]interface{}{"username": node.currentUser, "capabilities": node.capabilities},
+		}})
+	case path == "shcluster/captain/info" && node.shcCaptain != nil:
+		writeFakeEntries(w, []map[string]interface{}{node.shcCaptain})
+	case path == "shcluster/member/members" && node.shcMembers != nil:
+		writeFakeEntries(w, node.shcMembers)
+	default:
+		writeFakeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func writeFakeEntries(w http.ResponseWriter, entries []map[string]interface{}) {
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"entry": entries})
+}
+
+func writeFakeError(w http.ResponseWriter, status int, text string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"messages": []map[string]string{{"type": "ERROR", "text": text}},
+	})
+}
+
+// setFail makes API calls with any of methods fail, or none if methods is empty.
+func (node *testFakeNode) setFail(methods ...string) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.failMethods = make(map[string]bool)
+	for _, method := range methods {
+		node.failMethods[method] = true
+	}
+}
+
+func (node *testFakeNode) setDelay(delay time.Duration) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.delay = delay
+}
+
+func (node *testFakeNode) property(path string) string {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	return node.properties[path]
+}
+
+func (node *testFakeNode) hasUser(username string) bool {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	return node.users[username]
+}
+
+func (node *testFakeNode) password(username string) string {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	return node.passwords[username]
+}
+
+func (node *testFakeNode) addUser(username string) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.users[username] = true
+}
+
+func (node *testFakeNode) setSHC(captain map[string]interface{}, members []map[string]interface{}) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.shcCaptain = captain
+	node.shcMembers = members
+}
+
+func (node *testFakeNode) setCurrentContext(username string, capabilities ...string) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.currentUser = username
+	node.capabilities = capabilities
+}
+
+// hostPort returns the address of the node, e.g., for use as server name in a node_url_template.
+func (node *testFakeNode) hostPort() string {
+	return strings.TrimPrefix(node.URL, "http://")
+}
+
+// testFakeNodesBackend returns a backend with connection "testconn", which has a static node
+// inventory of the fake nodes, named after their index: "node0", "node1", etc.
+func testFakeNodesBackend(t *testing.T, nodes ...*testFakeNode) (*backend, logical.Storage) {
+	t.Helper()
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	b, err := Factory(context.Background(), config)
+	assert.NilError(t, err)
+
+	conn := &splunkConfig{
+		URL:          nodes[0].URL,
+		Username:     "admin",
+		Password:     "adminpw",
+		AllowedRoles: []string{"*"},
+	}
+	for i, node := range nodes {
+		conn.Nodes = append(conn.Nodes, staticNode{
+			FQDN:        testFakeNodeName(i),
+			URL:         node.URL,
+			ServerRoles: []string{"indexer"},
+		})
+	}
+	assert.NilError(t, conn.store(context.Background(), config.StorageView, "testconn"))
+	return b.(*backend), config.StorageView
+}
+
+func testFakeNodeName(i int) string {
+	return fmt.Sprintf("node%d", i)
+}
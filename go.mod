@@ -3,6 +3,7 @@ module github.com/splunk/vault-plugin-splunk
 go 1.18
 
 require (
+	github.com/armon/go-metrics v0.3.10
 	github.com/dghubble/sling v1.4.0
 	github.com/fatih/structs v1.1.0
 	github.com/google/go-querystring v1.1.0
@@ -43,7 +44,6 @@ require (
 	github.com/Nvveen/Gotty v0.0.0-20120604004816-cd527374f1e5 // indirect
 	github.com/StackExchange/wmi v1.2.1 // indirect
 	github.com/aliyun/alibaba-cloud-sdk-go v0.0.0-20190620160927-9418d7b0cd0f // indirect
-	github.com/armon/go-metrics v0.3.10 // indirect
 	github.com/armon/go-proxyproto v0.0.0-20210323213023-7e956b284f0a // indirect
 	github.com/armon/go-radix v1.0.0 // indirect
 	github.com/asaskevich/govalidator v0.0.0-20190424111038-f61b66f89f4a // indirect
@@ -0,0 +1,64 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fatih/structs"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const hecRolesPrefix = "hec-roles/"
+
+type hecRoleConfig struct {
+	Connection string        `json:"connection" structs:"connection"`
+	DefaultTTL time.Duration `json:"default_ttl" structs:"default_ttl"`
+	MaxTTL     time.Duration `json:"max_ttl" structs:"max_ttl"`
+
+	// HTTP Event Collector input attributes
+	Index          string   `json:"index" structs:"index"`
+	AllowedIndexes []string `json:"allowed_indexes" structs:"allowed_indexes"`
+	Sourcetype     string   `json:"sourcetype,omitempty" structs:"sourcetype"`
+	Source         string   `json:"source,omitempty" structs:"source"`
+}
+
+// hecRoleConfigLoad returns nil if the HEC role named `name` does not exist in `storage`, otherwise
+// returns the HEC role.  The second return value is non-nil on error.
+func hecRoleConfigLoad(ctx context.Context, s logical.Storage, name string) (*hecRoleConfig, error) {
+	if name == "" {
+		return nil, fmt.Errorf("invalid HEC role name")
+	}
+
+	entry, err := s.Get(ctx, hecRolesPrefix+name)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving HEC role: %w", err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	role := hecRoleConfig{}
+	if err := entry.DecodeJSON(&role); err != nil {
+		return nil, fmt.Errorf("error decoding HEC role: %w", err)
+	}
+	return &role, nil
+}
+
+func (role *hecRoleConfig) store(ctx context.Context, s logical.Storage, name string) error {
+	entry, err := logical.StorageEntryJSON(hecRolesPrefix+name, role)
+	if err != nil {
+		return err
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return fmt.Errorf("error writing %q JSON: %w", hecRolesPrefix+name, err)
+	}
+	return nil
+}
+
+func (role *hecRoleConfig) toResponseData() map[string]interface{} {
+	data := structs.New(role).Map()
+	data["default_ttl"] = int64(role.DefaultTTL.Seconds())
+	data["max_ttl"] = int64(role.MaxTTL.Seconds())
+	return data
+}
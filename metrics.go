@@ -0,0 +1,77 @@
+package splunk
+
+import (
+	"context"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Telemetry is reported via the global go-metrics instance, like Vault's own metrics.
+//
+// Secrets are counted as splunk.creds.<op> and splunk.creds.<op>_failed.
+const (
+	metricCredsIssue  = "issue"
+	metricCredsRenew  = "renew"
+	metricCredsRevoke = "revoke"
+)
+
+var metricConnectionCache = []string{"splunk", "connection", "cache"}
+
+func credsMetric(op string) []string {
+	return []string{"splunk", "creds", op}
+}
+
+// secretLabels returns the metric labels for a secret with the given lease data.
+func secretLabels(secretType string, internalData map[string]interface{}) []metrics.Label {
+	labels := []metrics.Label{{Name: "type", Value: secretType}}
+	for _, key := range []string{"role", "connection"} {
+		value, _ := internalData[key].(string)
+		labels = append(labels, metrics.Label{Name: key, Value: value})
+	}
+	return labels
+}
+
+// withIssueMetrics counts the secrets of secretType issued by handler, and failed attempts.  The connection of
+// a failed attempt is not known.
+func withIssueMetrics(secretType string, handler framework.OperationFunc) framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		resp, err := handler(ctx, req, d)
+		switch {
+		case err != nil || (resp != nil && resp.IsError()):
+			labels := secretLabels(secretType, map[string]interface{}{"role": d.Get("name")})
+			metrics.IncrCounterWithLabels(credsMetric(metricCredsIssue+"_failed"), 1, labels)
+		case resp != nil && resp.Secret != nil:
+			labels := secretLabels(secretType, resp.Secret.InternalData)
+			metrics.IncrCounterWithLabels(credsMetric(metricCredsIssue), 1, labels)
+		}
+		return resp, err
+	}
+}
+
+// withSecretMetrics counts successful and failed renewals or revocations (op) of secretType by handler.
+func withSecretMetrics(secretType, op string, handler framework.OperationFunc) framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		resp, err := handler(ctx, req, d)
+		key := op
+		if err != nil || (resp != nil && resp.IsError()) {
+			key += "_failed"
+		}
+		labels := secretLabels(secretType, req.Secret.InternalData)
+		metrics.IncrCounterWithLabels(credsMetric(key), 1, labels)
+		return resp, err
+	}
+}
+
+// connectionCacheAccessed counts hits and misses of the connection cache.
+func connectionCacheAccessed(config *splunkConfig, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	metrics.IncrCounterWithLabels(metricConnectionCache, 1, []metrics.Label{
+		{Name: "connection", Value: config.name},
+		{Name: "result", Value: result},
+	})
+}
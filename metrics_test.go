@@ -0,0 +1,85 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/v3/assert"
+)
+
+// testMetricsSink collects the metrics reported during the calling test.
+func testMetricsSink(t *testing.T) *metrics.InmemSink {
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	cfg := metrics.DefaultConfig("test")
+	cfg.EnableHostname = false
+	cfg.EnableRuntimeMetrics = false
+	if _, err := metrics.NewGlobal(cfg, sink); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_, _ = metrics.NewGlobal(metrics.DefaultConfig("test"), &metrics.BlackholeSink{})
+	})
+	return sink
+}
+
+func Test_withIssueMetrics(t *testing.T) {
+	sink := testMetricsSink(t)
+
+	var resp *logical.Response
+	var err error
+	handler := withIssueMetrics(secretCredsType,
+		func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+			return resp, err
+		})
+	d := &framework.FieldData{
+		Raw:    map[string]interface{}{"name": "admin"},
+		Schema: map[string]*framework.FieldSchema{"name": {Type: framework.TypeString}},
+	}
+	resp = &logical.Response{Secret: &logical.Secret{InternalData: map[string]interface{}{
+		"role":       "admin",
+		"connection": "local",
+	}}}
+	_, _ = handler(context.Background(), &logical.Request{}, d)
+	resp, err = nil, fmt.Errorf("issue failed")
+	_, _ = handler(context.Background(), &logical.Request{}, d)
+	resp, err = logical.ErrorResponse("role not found"), nil
+	_, _ = handler(context.Background(), &logical.Request{}, d)
+
+	intervals := sink.Data()
+	assert.Assert(t, len(intervals) > 0)
+	counters := intervals[0].Counters
+	assert.Equal(t, counters["test.splunk.creds.issue;type=creds;role=admin;connection=local"].Count, 1)
+	assert.Equal(t, counters["test.splunk.creds.issue_failed;type=creds;role=admin;connection="].Count, 2)
+}
+
+func Test_withSecretMetrics(t *testing.T) {
+	sink := testMetricsSink(t)
+
+	fail := false
+	handler := withSecretMetrics(secretCredsType, metricCredsRevoke,
+		func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+			if fail {
+				return nil, fmt.Errorf("revoke failed")
+			}
+			return nil, nil
+		})
+	req := &logical.Request{Secret: &logical.Secret{InternalData: map[string]interface{}{
+		"role":       "admin",
+		"connection": "local",
+	}}}
+	_, _ = handler(context.Background(), req, nil)
+	fail = true
+	_, _ = handler(context.Background(), req, nil)
+
+	intervals := sink.Data()
+	assert.Assert(t, len(intervals) > 0)
+	counters := intervals[0].Counters
+	labels := ";type=creds;role=admin;connection=local"
+	assert.Equal(t, counters["test.splunk.creds.revoke"+labels].Count, 1)
+	assert.Equal(t, counters["test.splunk.creds.revoke_failed"+labels].Count, 1)
+}
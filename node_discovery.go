@@ -0,0 +1,256 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+
+	"github.com/splunk/vault-plugin-splunk/clients/splunk"
+)
+
+// Sources for discovering the nodes of multi-node connections.
+const (
+	nodeDiscoverySearchPeers    = "search_peers"
+	nodeDiscoveryClusterManager = "cluster_manager"
+	nodeDiscoverySHCMembers     = "shc_members"
+	nodeDiscoveryStatic         = "static"
+)
+
+// allNodes is the node_fqdn for creating credentials on all nodes with allowed server roles.
+const allNodes = "all"
+
+var nodeDiscoverySources = []string{nodeDiscoverySearchPeers, nodeDiscoveryClusterManager, nodeDiscoverySHCMembers, nodeDiscoveryStatic}
+
+// discoveredNode is a node of a multi-node deployment, independent of the discovery source.
+//
+// The exported fields are available in node_url_template.
+type discoveredNode struct {
+	// Host is used for connecting to the node
+	Host       string
+	HostFQDN   string
+	ServerName string
+	GUID       string
+	// Site and Status are empty for search peers
+	Site        string
+	Status      string
+	ServerRoles []string
+
+	// static is the inventory entry for static node discovery
+	static *staticNode
+}
+
+// exampleNode is used for validating node_url_template.
+var exampleNode = &discoveredNode{
+	Host:        "idx1.example.com",
+	HostFQDN:    "idx1.example.com",
+	ServerName:  "idx1",
+	GUID:        "00000000-0000-0000-0000-000000000000",
+	Site:        "site1",
+	Status:      "Up",
+	ServerRoles: []string{"indexer"},
+}
+
+// matches returns true if nodeFQDN denotes the node, by host name, FQDN or server name.
+func (n *discoveredNode) matches(nodeFQDN string) bool {
+	return strings.EqualFold(n.HostFQDN, nodeFQDN) || strings.EqualFold(n.Host, nodeFQDN) ||
+		strings.EqualFold(n.ServerName, nodeFQDN)
+}
+
+// available returns false if the discovery source reported the node as not being up.
+func (n *discoveredNode) available() bool {
+	return n.Status == "" || strings.EqualFold(n.Status, "Up")
+}
+
+func (n *discoveredNode) toResponseData() map[string]interface{} {
+	return map[string]interface{}{
+		"host":         n.Host,
+		"host_fqdn":    n.HostFQDN,
+		"server_name":  n.ServerName,
+		"guid":         n.GUID,
+		"site":         n.Site,
+		"status":       n.Status,
+		"server_roles": n.ServerRoles,
+	}
+}
+
+// allowed returns true if the node has any of the allowed server roles of roleConfig.
+func (n *discoveredNode) allowed(roleConfig *roleConfig) bool {
+	if strutil.StrListContains(roleConfig.AllowedServerRoles, "*") {
+		return true
+	}
+	for _, role := range n.ServerRoles {
+		if strutil.StrListContainsGlob(roleConfig.AllowedServerRoles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeDiscovery returns the effective discovery source of a connection.
+func (config *splunkConfig) nodeDiscovery() string {
+	if config.NodeDiscovery == "" {
+		if len(config.Nodes) > 0 {
+			return nodeDiscoveryStatic
+		}
+		return nodeDiscoverySearchPeers
+	}
+	return config.NodeDiscovery
+}
+
+// discoverNodes returns the nodes of a multi-node connection from its configured discovery source.
+func (b *backend) discoverNodes(ctx context.Context, config *splunkConfig) ([]discoveredNode, error) {
+	if config.nodeDiscovery() == nodeDiscoveryStatic {
+		return nodesFromInventory(config.Nodes), nil
+	}
+
+	conn, err := b.ensureConnection(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	switch source := config.nodeDiscovery(); source {
+	case nodeDiscoverySearchPeers:
+		nodes, err := collectNodes(conn.Deployment.SearchPeersPager(ctx, splunk.ServerInfoEntryFilterMinimal), nodesFromSearchPeers)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read search peers: %w", err)
+		}
+		return nodes, nil
+	case nodeDiscoveryClusterManager:
+		nodes, err := collectNodes(conn.Deployment.ClusterPeersPager(ctx, splunk.ClusterPeerEntryFilterDefault), nodesFromClusterPeers)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read peers from cluster manager: %w", err)
+		}
+		return nodes, nil
+	case nodeDiscoverySHCMembers:
+		nodes, err := collectNodes(conn.Deployment.SHCMembersPager(ctx, splunk.SHCMemberEntryFilterDefault), nodesFromSHCMembers)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read search head cluster members: %w", err)
+		}
+		return nodes, nil
+	default:
+		return nil, fmt.Errorf("unknown node discovery source %q", source)
+	}
+}
+
+// collectNodes returns the nodes of all pages of pager, converted one page at a time.
+func collectNodes[T any](pager *splunk.Pager[T], convert func([]T) []discoveredNode) ([]discoveredNode, error) {
+	nodes := make([]discoveredNode, 0)
+	for pager.Next() {
+		nodes = append(nodes, convert(pager.Page())...)
+	}
+	return nodes, pager.Err()
+}
+
+// resolveNode returns the node nodeFQDN for use in node_url_template.  Nodes are only discovered
+// if a custom template or a static inventory is configured; unknown nodes only provide Host.
+func (b *backend) resolveNode(ctx context.Context, config *splunkConfig, nodeFQDN string) (*discoveredNode, error) {
+	node := &discoveredNode{Host: nodeFQDN}
+	if config.NodeURLTemplate == "" && config.nodeDiscovery() != nodeDiscoveryStatic {
+		return node, nil
+	}
+	nodes, err := b.discoverNodes(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve node %q for node_url_template: %w", nodeFQDN, err)
+	}
+	for i := range nodes {
+		if nodes[i].matches(nodeFQDN) {
+			return &nodes[i], nil
+		}
+	}
+	b.Logger().Debug("node not discovered, using host only", "nodeFQDN", nodeFQDN)
+	return node, nil
+}
+
+func nodesFromInventory(inventory []staticNode) []discoveredNode {
+	nodes := make([]discoveredNode, 0, len(inventory))
+	for i := range inventory {
+		nodes = append(nodes, discoveredNode{
+			Host:        inventory[i].FQDN,
+			HostFQDN:    inventory[i].FQDN,
+			Site:        inventory[i].Site,
+			ServerRoles: inventory[i].ServerRoles,
+			static:      &inventory[i],
+		})
+	}
+	return nodes
+}
+
+func nodesFromSearchPeers(peers []splunk.ServerInfoEntry) []discoveredNode {
+	nodes := make([]discoveredNode, 0, len(peers))
+	for _, peer := range peers {
+		nodes = append(nodes, discoveredNode{
+			// the actual FQDN as returned by the cluster master, confusingly
+			Host:        peer.Content.Host,
+			HostFQDN:    peer.Content.HostFQDN,
+			ServerName:  peer.Content.ServerName,
+			GUID:        peer.Content.GUID,
+			ServerRoles: peer.Content.Roles,
+		})
+	}
+	return nodes
+}
+
+func nodesFromClusterPeers(peers []splunk.ClusterPeerEntry) []discoveredNode {
+	nodes := make([]discoveredNode, 0, len(peers))
+	for _, peer := range peers {
+		host, _, err := net.SplitHostPort(peer.Content.HostPortPair)
+		if err != nil {
+			host = peer.Content.HostPortPair
+		}
+		nodes = append(nodes, discoveredNode{
+			Host:        host,
+			ServerName:  peer.Content.Label,
+			GUID:        peer.Name,
+			ServerRoles: []string{"indexer"},
+			Site:        peer.Content.Site,
+			Status:      peer.Content.Status,
+		})
+	}
+	return nodes
+}
+
+func nodesFromSHCMembers(members []splunk.SHCMemberEntry) []discoveredNode {
+	nodes := make([]discoveredNode, 0, len(members))
+	for _, member := range members {
+		nodes = append(nodes, discoveredNode{
+			Host:        hostFromMgmtURI(member.Content.MgmtURI, member.Content.Label),
+			ServerName:  member.Content.Label,
+			GUID:        member.Name,
+			ServerRoles: []string{"search_head", "shc_member"},
+			Site:        member.Content.Site,
+			Status:      member.Content.Status,
+		})
+	}
+	return nodes
+}
+
+// hostFromMgmtURI returns the host of a management URI like "https://sh1.example.com:8089", or fallback.
+func hostFromMgmtURI(mgmtURI, fallback string) string {
+	if u, err := url.Parse(mgmtURI); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return fallback
+}
+
+func findNode(nodeFQDN string, nodes []discoveredNode, roleConfig *roleConfig) (*discoveredNode, error) {
+	for i := range nodes {
+		node := &nodes[i]
+		// check if node_fqdn is in either of HostFQDN, Host or ServerName. User might not always the FQDN on the cli input
+		if !node.matches(nodeFQDN) {
+			continue
+		}
+		if !node.available() {
+			return nil, fmt.Errorf("host %q is not available: status %q", nodeFQDN, node.Status)
+		}
+		// Return node if the requested node type is allowed
+		if node.allowed(roleConfig) {
+			return node, nil
+		}
+		return nil, fmt.Errorf("host %q does not have any of the allowed server roles: %q", nodeFQDN, roleConfig.AllowedServerRoles)
+	}
+	return nil, fmt.Errorf("host %q not found", nodeFQDN)
+}
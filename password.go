@@ -1,15 +1,30 @@
 package splunk
 
 import (
+	"fmt"
+	"unicode"
+
 	"github.com/sethvargo/go-password/password"
 )
 
+const (
+	// DefaultPasswordSymbols is a mostly shell-safe set, TE-101
+	DefaultPasswordSymbols = "_&^%$#@!"
+
+	// minPasswordLength is the default "minPasswordLength" in Splunk's authentication.conf
+	minPasswordLength = 8
+)
+
 type PasswordSpec struct {
-	Length      int  `json:"length" structs:"length"`
-	NumDigits   int  `json:"num_digits" structs:"num_digits"`
-	NumSymbols  int  `json:"num_symbols" structs:"num_symbols"`
-	AllowUpper  bool `json:"allow_upper" structs:"allow_upper"`
-	AllowRepeat bool `json:"allow_repeat" structs:"allow_repeat"`
+	Length      int    `json:"length" structs:"length"`
+	NumDigits   int    `json:"num_digits" structs:"num_digits"`
+	NumSymbols  int    `json:"num_symbols" structs:"num_symbols"`
+	Symbols     string `json:"symbols,omitempty" structs:"symbols"`
+	AllowUpper  bool   `json:"allow_upper" structs:"allow_upper"`
+	AllowRepeat bool   `json:"allow_repeat" structs:"allow_repeat"`
+
+	// Policy names a Vault password policy.  If set, it takes precedence over all other settings.
+	Policy string `json:"policy,omitempty" structs:"policy"`
 }
 
 func DefaultPasswordSpec() *PasswordSpec {
@@ -17,24 +32,72 @@ func DefaultPasswordSpec() *PasswordSpec {
 		Length:      32,
 		NumDigits:   4,
 		NumSymbols:  4,
+		Symbols:     DefaultPasswordSymbols,
 		AllowUpper:  true,
 		AllowRepeat: true,
 	}
 }
 
+// symbols returns the symbol alphabet, falling back to the default for specs stored by older versions.
+func (spec *PasswordSpec) symbols() string {
+	if spec.Symbols == "" {
+		return DefaultPasswordSymbols
+	}
+	return spec.Symbols
+}
+
+// Validate checks the spec against Splunk's minimum password requirements, and
+// whether passwords can be generated from it at all.
+func (spec *PasswordSpec) Validate() error {
+	if spec.Policy != "" {
+		// validated against the system view by the caller
+		return nil
+	}
+	if spec.Length < minPasswordLength {
+		return fmt.Errorf("password length must be at least %d", minPasswordLength)
+	}
+	if spec.NumDigits < 0 || spec.NumSymbols < 0 {
+		return fmt.Errorf("number of password digits and symbols cannot be negative")
+	}
+	if spec.NumDigits+spec.NumSymbols > spec.Length {
+		return fmt.Errorf("number of password digits and symbols exceeds password length %d", spec.Length)
+	}
+	for _, r := range spec.Symbols {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) || unicode.IsSpace(r) || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return fmt.Errorf("invalid password symbol %q", r)
+		}
+	}
+
+	if !spec.AllowRepeat {
+		letters := len(password.LowerLetters)
+		if spec.AllowUpper {
+			letters += len(password.UpperLetters)
+		}
+		switch {
+		case spec.NumDigits > len(password.Digits):
+			return password.ErrDigitsExceedsAvailable
+		case spec.NumSymbols > len(spec.symbols()):
+			return password.ErrSymbolsExceedsAvailable
+		case spec.Length-spec.NumDigits-spec.NumSymbols > letters:
+			return password.ErrLettersExceedsAvailable
+		}
+	}
+	return nil
+}
+
 func GeneratePassword(spec *PasswordSpec) (string, error) {
+	if spec == nil {
+		spec = DefaultPasswordSpec()
+	}
+
 	passwdgen, err := password.NewGenerator(&password.GeneratorInput{
 		LowerLetters: password.LowerLetters,
 		UpperLetters: password.UpperLetters,
 		Digits:       password.Digits,
-		Symbols:      "_&^%$#@!", // mostly shell-safe set, TE-101
+		Symbols:      spec.symbols(),
 	})
 	if err != nil {
 		return "", err
 	}
-
-	if spec == nil {
-		spec = DefaultPasswordSpec()
-	}
 	return passwdgen.Generate(spec.Length, spec.NumDigits, spec.NumSymbols, !spec.AllowUpper, spec.AllowRepeat)
 }
@@ -0,0 +1,115 @@
+package splunk
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestPasswordSpec_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(spec *PasswordSpec)
+		wantErr string
+	}{
+		{
+			name:   "default",
+			modify: func(spec *PasswordSpec) {},
+		},
+		{
+			name:    "too short",
+			modify:  func(spec *PasswordSpec) { spec.Length = 7 },
+			wantErr: "password length must be at least 8",
+		},
+		{
+			name: "digits and symbols exceed length",
+			modify: func(spec *PasswordSpec) {
+				spec.Length = 8
+				spec.NumDigits = 5
+				spec.NumSymbols = 4
+			},
+			wantErr: "number of password digits and symbols exceeds password length 8",
+		},
+		{
+			name:    "negative digits",
+			modify:  func(spec *PasswordSpec) { spec.NumDigits = -1 },
+			wantErr: "number of password digits and symbols cannot be negative",
+		},
+		{
+			name:    "letter as symbol",
+			modify:  func(spec *PasswordSpec) { spec.Symbols = "_a" },
+			wantErr: `invalid password symbol 'a'`,
+		},
+		{
+			name:    "space as symbol",
+			modify:  func(spec *PasswordSpec) { spec.Symbols = "_ " },
+			wantErr: `invalid password symbol ' '`,
+		},
+		{
+			name: "symbols exceed alphabet without repeats",
+			modify: func(spec *PasswordSpec) {
+				spec.Symbols = "_-"
+				spec.AllowRepeat = false
+			},
+			wantErr: "number of symbols exceeds available symbols and repeats are not allowed",
+		},
+		{
+			name: "letters exceed alphabet without repeats",
+			modify: func(spec *PasswordSpec) {
+				spec.Length = 40
+				spec.AllowUpper = false
+				spec.AllowRepeat = false
+			},
+			wantErr: "number of letters exceeds available letters and repeats are not allowed",
+		},
+		{
+			name: "policy overrides",
+			modify: func(spec *PasswordSpec) {
+				spec.Length = 0
+				spec.Policy = "splunk"
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := DefaultPasswordSpec()
+			tt.modify(spec)
+			err := spec.Validate()
+			if tt.wantErr == "" {
+				assert.NilError(t, err)
+			} else {
+				assert.Error(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGeneratePassword(t *testing.T) {
+	spec := &PasswordSpec{
+		Length:      16,
+		NumDigits:   2,
+		NumSymbols:  6,
+		Symbols:     "-_",
+		AllowUpper:  false,
+		AllowRepeat: true,
+	}
+	assert.NilError(t, spec.Validate())
+
+	passwd, err := GeneratePassword(spec)
+	assert.NilError(t, err)
+	assert.Equal(t, len(passwd), spec.Length)
+	assert.Equal(t, strings.Count(passwd, "-")+strings.Count(passwd, "_"), spec.NumSymbols)
+	assert.Equal(t, strings.ToLower(passwd), passwd)
+}
+
+func TestGeneratePassword_LegacySpec(t *testing.T) {
+	// specs stored before symbols were configurable have no symbols
+	spec := DefaultPasswordSpec()
+	spec.Symbols = ""
+
+	passwd, err := GeneratePassword(spec)
+	assert.NilError(t, err)
+	assert.Equal(t, len(passwd), spec.Length)
+	assert.Assert(t, strings.ContainsAny(passwd, DefaultPasswordSymbols))
+}
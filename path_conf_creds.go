@@ -0,0 +1,76 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func (b *backend) pathConfCreds() *framework.Path {
+	return &framework.Path{
+		Pattern: "conf-creds/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the conf secret",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.confCredsReadHandler,
+		},
+
+		HelpSynopsis:    pathConfCredsHelpSyn,
+		HelpDescription: pathConfCredsHelpDesc,
+	}
+}
+
+func (b *backend) confCredsReadHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	secret, err := confSecretConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return logical.ErrorResponse(fmt.Sprintf("conf secret not found: %q", name)), nil
+	}
+
+	config, err := connectionConfigLoad(ctx, req.Storage, secret.Connection)
+	if err != nil {
+		return nil, err
+	}
+	if !strutil.StrListContains(config.AllowedRoles, "*") && !strutil.StrListContainsGlob(config.AllowedRoles, name) {
+		return logical.ErrorResponse("%q is not an allowed role for connection %q", name, secret.Connection), nil
+	}
+
+	nextRotation := secret.NextRotation()
+	ttl := rotationTTL(secret)
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"value":               secret.Value,
+			"file":                secret.File,
+			"stanza":              secret.Stanza,
+			"key":                 secret.Key,
+			"connection":          secret.Connection,
+			"node_fqdns":          secret.NodeFQDNs,
+			"rotation_period":     int64(secret.RotationPeriod.Seconds()),
+			"ttl":                 int64(ttl.Seconds()),
+			"last_vault_rotation": secret.LastRotated,
+			"next_vault_rotation": nextRotation,
+		},
+	}
+	return resp, nil
+}
+
+// #nosec G101
+const pathConfCredsHelpSyn = `
+Request the current value of a secret in a Splunk configuration file.
+`
+
+const pathConfCredsHelpDesc = `
+This path reads the current value of a conf secret, along with the
+times of the last and the next rotation.
+`
@@ -0,0 +1,205 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func (b *backend) pathConfSecrets() *framework.Path {
+	p := &framework.Path{
+		Pattern: confSecretsPrefix + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the conf secret",
+			},
+			"connection": {
+				Type:        framework.TypeString,
+				Description: "Name of the Splunk connection this secret is managed on",
+			},
+			"file": {
+				Type:        framework.TypeString,
+				Description: `Configuration file, without ".conf", e.g., "server"`,
+			},
+			"stanza": {
+				Type:        framework.TypeString,
+				Description: `Stanza in the configuration file, e.g., "general"`,
+			},
+			"key": {
+				Type:        framework.TypeString,
+				Description: `Key in the stanza, e.g., "pass4SymmKey"`,
+			},
+			"node_fqdns": {
+				Type: framework.TypeCommaStringSlice,
+				Description: trimIndent(`
+				Comma-separated list of nodes to write the secret to.  If empty, the secret
+				is written to the node of the connection URL.`),
+			},
+			"rotation_period": {
+				Type: framework.TypeDurationSecond,
+				Description: fmt.Sprintf("Period for automatic rotation.  Must be at least %s.",
+					minRotationPeriod),
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation:   b.confSecretsReadHandler,
+			logical.CreateOperation: b.confSecretsWriteHandler,
+			logical.UpdateOperation: b.confSecretsWriteHandler,
+			logical.DeleteOperation: b.confSecretsDeleteHandler,
+		},
+		ExistenceCheck:  b.confSecretsExistenceCheckHandler,
+		HelpSynopsis:    pathConfSecretHelpSyn,
+		HelpDescription: pathConfSecretHelpDesc,
+	}
+	addPasswordSpecFields(p.Fields)
+	return p
+}
+
+func (b *backend) confSecretsExistenceCheckHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (bool, error) {
+	name := d.Get("name").(string)
+	secret, err := confSecretConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return false, err
+	}
+	return secret != nil, nil
+}
+
+func (b *backend) confSecretsReadHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	secret, err := confSecretConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return nil, nil
+	}
+
+	resp := &logical.Response{
+		Data: secret.toResponseData(),
+	}
+	return resp, nil
+}
+
+func (b *backend) confSecretsWriteHandler(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.confSecretLock.Lock()
+	defer b.confSecretLock.Unlock()
+
+	name := data.Get("name").(string)
+	secret, err := confSecretConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		secret = &confSecretConfig{}
+	}
+	oldSecret := *secret
+
+	if connRaw, ok := getValue(data, req.Operation, "connection"); ok {
+		secret.Connection = connRaw.(string)
+	}
+	if secret.Connection == "" {
+		return logical.ErrorResponse("empty Splunk connection name"), nil
+	}
+	if fileRaw, ok := getValue(data, req.Operation, "file"); ok {
+		secret.File = fileRaw.(string)
+	}
+	if stanzaRaw, ok := getValue(data, req.Operation, "stanza"); ok {
+		secret.Stanza = stanzaRaw.(string)
+	}
+	if keyRaw, ok := getValue(data, req.Operation, "key"); ok {
+		secret.Key = keyRaw.(string)
+	}
+	if secret.File == "" || secret.Stanza == "" || secret.Key == "" {
+		return logical.ErrorResponse("file, stanza and key cannot be empty"), nil
+	}
+	if nodeFQDNsRaw, ok := getValue(data, req.Operation, "node_fqdns"); ok {
+		secret.NodeFQDNs = nodeFQDNsRaw.([]string)
+	}
+	if rotationPeriodRaw, ok := getValue(data, req.Operation, "rotation_period"); ok {
+		secret.RotationPeriod = time.Duration(rotationPeriodRaw.(int)) * time.Second
+	}
+	if secret.RotationPeriod < minRotationPeriod {
+		return logical.ErrorResponse("rotation_period must be at least %s", minRotationPeriod), nil
+	}
+	if secret.PasswordSpec == nil {
+		secret.PasswordSpec = DefaultPasswordSpec()
+	}
+	if resp, err := b.updatePasswordSpec(ctx, secret.PasswordSpec, data, req.Operation); resp != nil || err != nil {
+		return resp, err
+	}
+
+	config, err := connectionConfigLoad(ctx, req.Storage, secret.Connection)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if !strutil.StrListContains(config.AllowedRoles, "*") && !strutil.StrListContainsGlob(config.AllowedRoles, name) {
+		return logical.ErrorResponse("%q is not an allowed role for connection %q", name, secret.Connection), nil
+	}
+
+	// a new secret, or a secret at a different location needs a value managed by Vault
+	if req.Operation == logical.CreateOperation || !secret.sameLocation(&oldSecret) {
+		if err := b.rotateSecret(ctx, req.Storage, confSecretSecrets, name, secret); err != nil {
+			return logical.ErrorResponse("error setting initial value for %s: %s", secret.location(), err), nil
+		}
+		return nil, nil
+	}
+
+	if err := secret.store(ctx, req.Storage, name); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (b *backend) confSecretsDeleteHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	b.confSecretLock.Lock()
+	defer b.confSecretLock.Unlock()
+
+	name := d.Get("name").(string)
+	if err := req.Storage.Delete(ctx, confSecretsPrefix+name); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (b *backend) pathConfSecretsList() *framework.Path {
+	return &framework.Path{
+		Pattern: confSecretsPrefix + "?$",
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.confSecretsListHandler,
+		},
+		HelpSynopsis:    pathConfSecretHelpSyn,
+		HelpDescription: pathConfSecretHelpDesc,
+	}
+}
+
+func (b *backend) confSecretsListHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	entries, err := req.Storage.List(ctx, confSecretsPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(entries), nil
+}
+
+const pathConfSecretHelpSyn = `
+Manage secrets in Splunk configuration files.
+`
+
+const pathConfSecretHelpDesc = `
+This path lets you manage secrets in Splunk configuration files, like
+"pass4SymmKey" in server.conf, outputs.conf or inputs.conf.  Vault
+takes over the value: it is rotated when the conf secret is created,
+and then every "rotation_period".  New values are written via the
+Splunk properties API to all nodes listed in "node_fqdns".
+
+Note that Splunk may need a restart to pick up the new value; Vault
+does not restart Splunk.
+
+See the documentation for conf-secrets/name for a full list of accepted
+parameters.
+`
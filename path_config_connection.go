@@ -3,10 +3,12 @@ package splunk
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
@@ -28,15 +30,69 @@ func (b *backend) pathConfigConnection() *framework.Path {
 				Type:        framework.TypeString,
 				Description: "Admin password.",
 			},
+			"token": {
+				Type: framework.TypeString,
+				Description: trimIndent(`
+				Splunk authentication token of the admin user, used instead of the password.`),
+			},
+			"token_id": {
+				Type:        framework.TypeString,
+				Description: "ID of the admin token, for revoking it when the token is rotated.",
+			},
 			"url": {
 				Type:        framework.TypeString,
 				Description: "Splunk server URL.",
 			},
+			"failover_urls": {
+				Type: framework.TypeCommaStringSlice,
+				Description: trimIndent(`
+				Comma-separated list of further Splunk server URLs of the same deployment.
+				If the URL is unavailable, these are used in order.`),
+			},
 			"is_standalone": {
 				Type:        framework.TypeBool,
 				Description: `Whether this is a standalone or multi-node deployment.  Default: false`,
 				Default:     false,
 			},
+			"node_discovery": {
+				Type: framework.TypeString,
+				Description: trimIndent(`
+				Source for discovering the nodes of a multi-node deployment: "search_peers"
+				(search/distributed/peers), "cluster_manager" (peers of the indexer cluster
+				managed by the connection URL), or "shc_members" (members of the search head
+				cluster the connection URL belongs to), or "static" (the "nodes" inventory).
+				Default: "static" if "nodes" is set, "search_peers" otherwise`),
+			},
+			"nodes": {
+				Type: framework.TypeSlice,
+				Description: trimIndent(`
+				Static node inventory, for clusters without a cluster manager.  A list of
+				objects with "fqdn", "server_roles", and optionally "url" (default: from
+				node_url_template), "site", "username" and "password" (default: the
+				connection's admin credentials).`),
+			},
+			"node_url_template": {
+				Type: framework.TypeString,
+				Description: trimIndent(`
+				Go template for the URLs of nodes of a multi-node deployment, with the
+				variables .Host, .HostFQDN, .ServerName, .GUID and .Site of the
+				discovered node.  Default: "https://{{.Host}}:8089"`),
+			},
+			"shc_captain": {
+				Type:    framework.TypeBool,
+				Default: false,
+				Description: trimIndent(`
+				Whether the connection URL belongs to a search head cluster.  If set, users
+				are created and deleted on the current captain, which replicates them to all
+				members.  Default: false`),
+			},
+			"shc_replication_timeout": {
+				Type: framework.TypeDurationSecond,
+				Description: trimIndent(`
+				Time to wait for new users to be replicated to all search head cluster
+				members before returning credentials.  If 0, credentials are returned
+				immediately.  Requires shc_captain.  Default: 0`),
+			},
 			"allowed_roles": {
 				Type: framework.TypeCommaStringSlice,
 				Description: trimIndent(`
@@ -88,6 +144,55 @@ func (b *backend) pathConfigConnection() *framework.Path {
 				Default:     "30s",
 				Description: `The connection timeout to use.  Default: 30s.`,
 			},
+			"retry_max_attempts": {
+				Type:    framework.TypeInt,
+				Default: defaultRetryMaxAttempts,
+				Description: trimIndent(`
+				Maximum number of attempts for Splunk API calls failing transiently, including
+				the first one.  1 disables retries, 0 uses the default.  Default: 3`),
+			},
+			"retry_backoff": {
+				Type:    framework.TypeDurationSecond,
+				Default: "1s",
+				Description: trimIndent(`
+				Time before the first retry, doubling with every further attempt.  A Retry-After
+				header from Splunk takes precedence.  Default: 1s`),
+			},
+			"retry_max_backoff": {
+				Type:        framework.TypeDurationSecond,
+				Default:     "30s",
+				Description: "Maximum time between attempts.  Default: 30s",
+			},
+			"retry_status_codes": {
+				Type: framework.TypeCommaIntSlice,
+				Description: trimIndent(`
+				Comma-separated list of HTTP status codes to retry.  Default: 429,502,503,504`),
+			},
+			"rotation_period": {
+				Type: framework.TypeDurationSecond,
+				Description: trimIndent(`
+				Period for automatic rotation of the admin password.  If 0, the password
+				is only rotated via "rotate-root".  Default: 0`),
+			},
+			"rotation_window": {
+				Type: framework.TypeDurationSecond,
+				Description: trimIndent(`
+				Time after a scheduled rotation during which the rotation may still be
+				attempted.  If the window is missed, the rotation is skipped until the next
+				period.  If 0, rotations are attempted until they succeed.  Default: 0`),
+			},
+			"tidy_period": {
+				Type: framework.TypeDurationSecond,
+				Description: trimIndent(`
+				Period for automatically deleting orphaned users created by Vault.  If 0,
+				users are only deleted via "tidy".  Default: 0`),
+			},
+			"tidy_safety_buffer": {
+				Type: framework.TypeDurationSecond,
+				Description: trimIndent(`
+				Time that a user created by Vault must have been without a lease before
+				tidy deletes it.  If 0, the default is used.  Default: 72h`),
+			},
 		},
 
 		ExistenceCheck: b.connectionExistenceCheck,
@@ -115,13 +220,33 @@ func (b *backend) connectionReadHandler(ctx context.Context, req *logical.Reques
 		return nil, err
 	}
 
+	state, err := rootRotationStateLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+
 	resp := &logical.Response{
 		Data: config.toResponseData(),
 	}
+	state.addResponseData(resp.Data)
+	if state.Failures > 0 {
+		resp.AddWarning(fmt.Sprintf("scheduled root rotation failed %d time(s): %s", state.Failures, state.LastError))
+	}
+
+	tidy, err := tidyStateLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if !tidy.LastTidy.IsZero() {
+		resp.Data["last_tidy"] = tidy.LastTidy
+	}
 	return resp, nil
 }
 
 func (b *backend) connectionDeleteHandler(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
 	name := data.Get("name").(string)
 	config, err := connectionConfigLoad(ctx, req.Storage, name)
 	if err != nil {
@@ -131,6 +256,12 @@ func (b *backend) connectionDeleteHandler(ctx context.Context, req *logical.Requ
 	if err := req.Storage.Delete(ctx, fmt.Sprintf("config/%s", name)); err != nil {
 		return nil, fmt.Errorf("error reading connection configuration: %w", err)
 	}
+	if err := req.Storage.Delete(ctx, rootRotationPrefix+name); err != nil {
+		return nil, fmt.Errorf("error deleting root rotation state: %w", err)
+	}
+	if err := req.Storage.Delete(ctx, tidyStatePrefix+name); err != nil {
+		return nil, fmt.Errorf("error deleting tidy state: %w", err)
+	}
 
 	// XXXX WAL
 	if err := b.clearConnection(config.ID); err != nil {
@@ -140,6 +271,9 @@ func (b *backend) connectionDeleteHandler(ctx context.Context, req *logical.Requ
 }
 
 func (b *backend) connectionWriteHandler(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
 	name := data.Get("name").(string)
 	if name == "" {
 		return logical.ErrorResponse(respErrEmptyName), nil
@@ -160,8 +294,24 @@ func (b *backend) connectionWriteHandler(ctx context.Context, req *logical.Reque
 	if config.Username == "" {
 		return logical.ErrorResponse("empty username"), nil
 	}
+	// the admin authenticates either with a password or with a token
 	if passwordRaw, ok := getValue(data, req.Operation, "password"); ok {
 		config.Password = passwordRaw.(string)
+		config.Token = ""
+		config.TokenID = ""
+	}
+	if tokenRaw, ok := getValue(data, req.Operation, "token"); ok {
+		config.Token = tokenRaw.(string)
+		config.TokenID = ""
+		if config.Token != "" {
+			config.Password = ""
+		}
+	}
+	if tokenIDRaw, ok := getValue(data, req.Operation, "token_id"); ok {
+		config.TokenID = tokenIDRaw.(string)
+	}
+	if config.TokenID != "" && config.Token == "" {
+		return logical.ErrorResponse("token_id requires token"), nil
 	}
 	if urlRaw, ok := getValue(data, req.Operation, "url"); ok {
 		config.URL = urlRaw.(string)
@@ -169,9 +319,51 @@ func (b *backend) connectionWriteHandler(ctx context.Context, req *logical.Reque
 	if config.URL == "" {
 		return logical.ErrorResponse("empty URL"), nil
 	}
+	if failoverURLsRaw, ok := getValue(data, req.Operation, "failover_urls"); ok {
+		config.FailoverURLs = failoverURLsRaw.([]string)
+	}
+	for _, failoverURL := range config.FailoverURLs {
+		if u, err := url.Parse(failoverURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return logical.ErrorResponse("invalid failover URL %q: expected http(s)://host[:port]", failoverURL), nil
+		}
+	}
 	if isStandalone, ok := getValue(data, req.Operation, "is_standalone"); ok {
 		config.IsStandalone = isStandalone.(bool)
 	}
+	if nodeDiscoveryRaw, ok := getValue(data, req.Operation, "node_discovery"); ok {
+		config.NodeDiscovery = nodeDiscoveryRaw.(string)
+	}
+	if config.NodeDiscovery != "" && !strutil.StrListContains(nodeDiscoverySources, config.NodeDiscovery) {
+		return logical.ErrorResponse("node_discovery must be one of %q", nodeDiscoverySources), nil
+	}
+	if nodesRaw, ok := getValue(data, req.Operation, "nodes"); ok {
+		nodes, err := parseStaticNodes(nodesRaw.([]interface{}))
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		config.Nodes = nodes
+	}
+	if config.nodeDiscovery() == nodeDiscoveryStatic && len(config.Nodes) == 0 {
+		return logical.ErrorResponse("node_discovery %q requires nodes", nodeDiscoveryStatic), nil
+	}
+	if shcCaptainRaw, ok := getValue(data, req.Operation, "shc_captain"); ok {
+		config.SHCCaptain = shcCaptainRaw.(bool)
+	}
+	if shcReplicationTimeoutRaw, ok := getValue(data, req.Operation, "shc_replication_timeout"); ok {
+		config.SHCReplicationTimeout = time.Duration(shcReplicationTimeoutRaw.(int)) * time.Second
+	}
+	if config.SHCReplicationTimeout < 0 {
+		return logical.ErrorResponse("shc_replication_timeout cannot be negative"), nil
+	}
+	if config.SHCReplicationTimeout > 0 && !config.SHCCaptain {
+		return logical.ErrorResponse("shc_replication_timeout requires shc_captain"), nil
+	}
+	if nodeURLTemplateRaw, ok := getValue(data, req.Operation, "node_url_template"); ok {
+		config.NodeURLTemplate = nodeURLTemplateRaw.(string)
+	}
+	if _, err := config.nodeURL(exampleNode); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
 
 	if verifyRaw, ok := getValue(data, req.Operation, "verify"); ok {
 		config.Verify = verifyRaw.(bool)
@@ -238,13 +430,78 @@ func (b *backend) connectionWriteHandler(ctx context.Context, req *logical.Reque
 		config.ConnectTimeout = time.Duration(connectTimeoutRaw.(int)) * time.Second
 	}
 
+	if retryMaxAttemptsRaw, ok := getValue(data, req.Operation, "retry_max_attempts"); ok {
+		config.RetryMaxAttempts = retryMaxAttemptsRaw.(int)
+	}
+	if config.RetryMaxAttempts < 0 {
+		return logical.ErrorResponse("retry_max_attempts cannot be negative"), nil
+	}
+	if retryBackoffRaw, ok := getValue(data, req.Operation, "retry_backoff"); ok {
+		config.RetryBackoff = time.Duration(retryBackoffRaw.(int)) * time.Second
+	}
+	if retryMaxBackoffRaw, ok := getValue(data, req.Operation, "retry_max_backoff"); ok {
+		config.RetryMaxBackoff = time.Duration(retryMaxBackoffRaw.(int)) * time.Second
+	}
+	if config.RetryBackoff < 0 || config.RetryMaxBackoff < 0 {
+		return logical.ErrorResponse("retry_backoff and retry_max_backoff cannot be negative"), nil
+	}
+	if retryStatusCodesRaw, ok := getValue(data, req.Operation, "retry_status_codes"); ok {
+		config.RetryStatusCodes = retryStatusCodesRaw.([]int)
+	}
+	for _, code := range config.RetryStatusCodes {
+		if code < 100 || code > 599 {
+			return logical.ErrorResponse("invalid HTTP status code in retry_status_codes: %d", code), nil
+		}
+	}
+
+	if rotationPeriodRaw, ok := getValue(data, req.Operation, "rotation_period"); ok {
+		config.RotationPeriod = time.Duration(rotationPeriodRaw.(int)) * time.Second
+	}
+	if config.RotationPeriod != 0 && config.RotationPeriod < minRotationPeriod {
+		return logical.ErrorResponse("rotation_period must be 0 or at least %s", minRotationPeriod), nil
+	}
+	if rotationWindowRaw, ok := getValue(data, req.Operation, "rotation_window"); ok {
+		config.RotationWindow = time.Duration(rotationWindowRaw.(int)) * time.Second
+	}
+	if config.RotationWindow < 0 {
+		return logical.ErrorResponse("rotation_window cannot be negative"), nil
+	}
+
+	if tidyPeriodRaw, ok := getValue(data, req.Operation, "tidy_period"); ok {
+		config.TidyPeriod = time.Duration(tidyPeriodRaw.(int)) * time.Second
+	}
+	if config.TidyPeriod != 0 && config.TidyPeriod < minRotationPeriod {
+		return logical.ErrorResponse("tidy_period must be 0 or at least %s", minRotationPeriod), nil
+	}
+	if tidySafetyBufferRaw, ok := getValue(data, req.Operation, "tidy_safety_buffer"); ok {
+		config.TidySafetyBuffer = time.Duration(tidySafetyBufferRaw.(int)) * time.Second
+	}
+	if config.TidySafetyBuffer < 0 {
+		return logical.ErrorResponse("tidy_safety_buffer cannot be negative"), nil
+	}
+
+	if config.Verify {
+		if err := config.verifyConnection(ctx); err != nil {
+			return logical.ErrorResponse("error verifying connection: %s", err), nil
+		}
+	}
+
 	if err := config.store(ctx, req.Storage, name); err != nil {
 		return nil, fmt.Errorf("error writing connection configuration: %w", err)
 	}
 
-	// if config.Verify {
-	// 	 config.verifyConnection(ctx, req.Storage, name)
-	// }
+	// credentials or schedule might have changed, start over
+	state, err := rootRotationStateLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	state.Failures = 0
+	state.LastError = ""
+	state.NextAttempt = time.Time{}
+	state.schedule(config, time.Now())
+	if err := state.store(ctx, req.Storage, name); err != nil {
+		return nil, err
+	}
 
 	return nil, nil
 }
@@ -305,5 +562,7 @@ should contain the same information; for convenience, the JSON format
 is the same as that output by the issue command from the PKI backend.
 
 When configuring the connection information, the backend will verify
-its validity.
+its validity, unless "verify" is set to false: it logs in to Splunk, and
+checks that the admin user has the capabilities for managing users.
+The configuration is only stored if verification succeeds.
 `
@@ -0,0 +1,124 @@
+package splunk
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/splunk/vault-plugin-splunk/clients/splunk"
+)
+
+func (b *backend) pathConnectionStatus() *framework.Path {
+	return &framework.Path{
+		Pattern: "config/" + framework.GenericNameRegex("name") + "/status",
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of this Splunk connection",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.connectionStatusHandler,
+		},
+
+		HelpSynopsis:    pathConnectionStatusHelpSyn,
+		HelpDescription: pathConnectionStatusHelpDesc,
+	}
+}
+
+func (b *backend) connectionStatusHandler(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	exists, err := connectionConfigExists(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return logical.ErrorResponse("connection configuration not found: %q", name), nil
+	}
+	config, err := connectionConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// a new connection, so that the credentials are actually checked
+	conn, err := config.newConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	info, resp, err := conn.Introspection.ServerInfo(ctx)
+	latency := time.Since(start)
+
+	status := map[string]interface{}{
+		"url":           config.URL,
+		"active_url":    conn.ActiveURL(),
+		"reachable":     true,
+		"authenticated": err == nil,
+		"latency_ms":    latency.Milliseconds(),
+	}
+	if err != nil {
+		status["error"] = err.Error()
+		if resp == nil || resp.HTTPResponse == nil {
+			// a failed login is reported as transport error, wrapping the APIError
+			var apiErr *splunk.APIError
+			status["reachable"] = errors.As(err, &apiErr)
+		} else if !splunk.IsUnauthorized(err) && !splunk.IsForbidden(err) {
+			// e.g., a server error, which does not tell whether the credentials are valid
+			delete(status, "authenticated")
+		}
+		return &logical.Response{Data: status}, nil
+	}
+	if len(info) > 0 {
+		content := info[0].Content
+		status["version"] = content.Version
+		status["build"] = content.Build
+		status["guid"] = content.GUID
+		status["server_name"] = content.ServerName
+		status["server_roles"] = content.Roles
+		status["product_type"] = content.ProductType
+		status["health"] = content.HealthInfo
+		status["license_state"] = content.LicenseState
+		status["active_license_group"] = content.ActiveLicenseGroup
+	}
+
+	if config.SHCCaptain {
+		captain, err := b.shcCaptain(ctx, config)
+		if err != nil {
+			status["shc_captain_error"] = err.Error()
+		} else {
+			status["shc_captain"] = captain.Host
+		}
+	}
+	if !config.IsStandalone {
+		status["node_discovery"] = config.nodeDiscovery()
+		nodes, err := b.discoverNodes(ctx, config)
+		if err != nil {
+			status["node_discovery_error"] = err.Error()
+		}
+		nodesData := make([]map[string]interface{}, 0, len(nodes))
+		for i := range nodes {
+			nodesData = append(nodesData, nodes[i].toResponseData())
+		}
+		status["nodes"] = nodesData
+	}
+	return &logical.Response{Data: status}, nil
+}
+
+const pathConnectionStatusHelpSyn = `
+Check a Splunk connection.
+`
+
+const pathConnectionStatusHelpDesc = `
+This path connects to Splunk with the configured credentials, and reports
+whether Splunk is reachable, whether authentication succeeded, and the
+latency of reading the server info.  If Splunk fails the request for
+other reasons, "authenticated" is left out.  The Splunk version, build, GUID,
+server roles and license state are reported as well.
+
+For multi-node connections, the discovered nodes are listed with their
+server roles and status, as used for node-specific credentials.
+`
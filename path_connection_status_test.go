@@ -0,0 +1,21 @@
+package splunk
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/v3/assert"
+)
+
+func TestBackend_connectionStatusServerError(t *testing.T) {
+	node := testNewFakeNode(t)
+	b, storage := testFakeNodesBackend(t, node)
+
+	node.setFail(http.MethodGet)
+	resp := testHandleRequest(t, b, storage, logical.ReadOperation, "config/testconn/status", nil)
+	assert.Equal(t, resp.Data["reachable"], true)
+	_, ok := resp.Data["authenticated"]
+	assert.Assert(t, !ok)
+	assert.Assert(t, resp.Data["error"].(string) != "")
+}
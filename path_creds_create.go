@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	uuid "github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault/sdk/framework"
@@ -23,7 +25,7 @@ func (b *backend) pathCredsCreate() *framework.Path {
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
-			logical.ReadOperation: b.credsReadHandler,
+			logical.ReadOperation: withIssueMetrics(secretCredsType, b.credsReadHandler),
 		},
 
 		HelpSynopsis:    pathCredsCreateHelpSyn,
@@ -41,12 +43,16 @@ func (b *backend) pathCredsCreateMulti() *framework.Path {
 			},
 			"node_fqdn": {
 				Type:        framework.TypeString,
-				Description: "FQDN for the Splunk Stack node",
+				Description: `FQDN for the Splunk Stack node, or "all" for all nodes with allowed server roles`,
+			},
+			"server_role": {
+				Type:        framework.TypeString,
+				Description: `Only create the user on nodes with this server role.  Requires node_fqdn "all".`,
 			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
-			logical.ReadOperation: b.credsReadHandler,
+			logical.ReadOperation: withIssueMetrics(secretCredsType, b.credsReadHandler),
 		},
 
 		HelpSynopsis:    pathCredsCreateHelpSyn,
@@ -63,6 +69,10 @@ func (b *backend) credsReadHandlerStandalone(ctx context.Context, req *logical.R
 	if role == nil {
 		return logical.ErrorResponse(fmt.Sprintf("role not found: %q", name)), nil
 	}
+	if len(role.Roles) == 0 && !role.DynamicRole {
+		// e.g., a role for tokens only
+		return logical.ErrorResponse("roles not configured for role %q", name), nil
+	}
 
 	config, err := connectionConfigLoad(ctx, req.Storage, role.Connection)
 	if err != nil {
@@ -74,7 +84,8 @@ func (b *backend) credsReadHandlerStandalone(ctx context.Context, req *logical.R
 		return nil, fmt.Errorf("%q is not an allowed role for connection %q", name, role.Connection)
 	}
 
-	conn, err := b.ensureConnection(ctx, config)
+	// the search head cluster captain for shc_captain
+	conn, err := b.ensureNodeConnection(ctx, config, "")
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +100,7 @@ func (b *backend) credsReadHandlerStandalone(ctx context.Context, req *logical.R
 		userPrefix = fmt.Sprintf("%s_%s", role.UserPrefix, req.DisplayName)
 	}
 	username := fmt.Sprintf("%s_%s", userPrefix, userUUID)
-	passwd, err := generateUserPassword(role)
+	passwd, err := b.generatePassword(ctx, role.PasswordSpec)
 	if err != nil {
 		return nil, fmt.Errorf("error generating new password %w", err)
 	}
@@ -101,47 +112,48 @@ func (b *backend) credsReadHandlerStandalone(ctx context.Context, req *logical.R
 		Email:      role.Email,
 		TZ:         role.TZ,
 	}
-	if _, _, err := conn.AccessControl.Authentication.Users.Create(&opts); err != nil {
+	walID, err := b.createUser(ctx, req.Storage, conn, name, role, "", &opts)
+	if err != nil {
 		return nil, err
 	}
+	var unreplicated []string
+	if config.SHCReplicationTimeout > 0 {
+		// on errors, the WAL rollback deletes the user again
+		if unreplicated, err = b.waitForSHCReplication(ctx, config, username); err != nil {
+			return nil, err
+		}
+	}
 
+	// the configured URL, rather than the search head cluster captain
 	resp := b.Secret(secretCredsType).Response(map[string]interface{}{
 		// return to user
 		"username":   username,
 		"password":   passwd,
-		"roles":      role.Roles,
+		"roles":      opts.Roles,
 		"connection": role.Connection,
-		"url":        conn.Params().BaseURL,
+		"url":        config.URL,
 	}, map[string]interface{}{
 		// store (with lease)
 		"username":   username,
 		"role":       name,
 		"connection": role.Connection,
-		"url":        conn.Params().BaseURL, // new in v0.7.0
+		"url":        config.URL, // new in v0.7.0
 	})
+	if role.DynamicRole {
+		resp.Secret.InternalData["splunk_role"] = dynamicRoleName(username)
+	}
 	resp.Secret.TTL = role.DefaultTTL
 	resp.Secret.MaxTTL = role.MaxTTL
+	if len(unreplicated) > 0 {
+		resp.AddWarning(fmt.Sprintf("user not yet replicated to search head cluster members after %s: %q",
+			config.SHCReplicationTimeout, unreplicated))
+	}
 
-	return resp, nil
-}
-
-func findNode(nodeFQDN string, hosts []splunk.ServerInfoEntry, roleConfig *roleConfig) (*splunk.ServerInfoEntry, error) {
-	for _, host := range hosts {
-		// check if node_fqdn is in either of HostFQDN or Host. User might not always the FQDN on the cli input
-		if strings.EqualFold(host.Content.HostFQDN, nodeFQDN) || strings.EqualFold(host.Content.Host, nodeFQDN) {
-			// Return host if the requested node type is allowed
-			if strutil.StrListContains(roleConfig.AllowedServerRoles, "*") {
-				return &host, nil
-			}
-			for _, role := range host.Content.Roles {
-				if strutil.StrListContainsGlob(roleConfig.AllowedServerRoles, role) {
-					return &host, nil
-				}
-			}
-			return nil, fmt.Errorf("host %q does not have any of the allowed server roles: %q", nodeFQDN, roleConfig.AllowedServerRoles)
-		}
+	if err := framework.DeleteWAL(ctx, req.Storage, walID); err != nil {
+		// the rollback would delete the user while the lease is active
+		return nil, fmt.Errorf("error deleting WAL for user %q: %w", username, err)
 	}
-	return nil, fmt.Errorf("host %q not found", nodeFQDN)
+	return resp, nil
 }
 
 func (b *backend) credsReadHandlerMulti(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
@@ -155,6 +167,10 @@ func (b *backend) credsReadHandlerMulti(ctx context.Context, req *logical.Reques
 	if role == nil {
 		return logical.ErrorResponse(fmt.Sprintf("role not found: %q", name)), nil
 	}
+	if len(role.Roles) == 0 && !role.DynamicRole {
+		// e.g., a role for tokens only
+		return logical.ErrorResponse("roles not configured for role %q", name), nil
+	}
 
 	config, err := connectionConfigLoad(ctx, req.Storage, role.Connection)
 	if err != nil {
@@ -170,28 +186,31 @@ func (b *backend) credsReadHandlerMulti(ctx context.Context, req *logical.Reques
 		return logical.ErrorResponse("%q is not an allowed role for connection %q", name, role.Connection), nil
 	}
 
-	conn, err := b.ensureConnection(ctx, config)
-	if err != nil {
-		return nil, err
+	serverRole := d.Get("server_role").(string)
+	if serverRole != "" && nodeFQDN != allNodes {
+		return logical.ErrorResponse("server_role requires node_fqdn %q", allNodes), nil
 	}
 
-	nodes, _, err := conn.Deployment.SearchPeers(splunk.ServerInfoEntryFilterMinimal)
+	nodes, err := b.discoverNodes(ctx, config)
 	if err != nil {
-		b.Logger().Error("Error while reading SearchPeers from cluster master", "err", err)
-		return nil, fmt.Errorf("unable to read searchpeers from cluster master: %w", err)
+		b.Logger().Error("Error while discovering nodes", "source", config.nodeDiscovery(), "err", err)
+		return nil, err
+	}
+	if nodeFQDN == allNodes {
+		return b.credsReadHandlerAll(ctx, req, name, role, config, nodes, serverRole)
 	}
 
 	foundNode, err := findNode(nodeFQDN, nodes, role)
 	if err != nil {
 		return logical.ErrorResponse(err.Error()), nil
 	}
-	if foundNode.Content.Host == "" {
+	if foundNode.Host == "" {
 		return nil, fmt.Errorf("host field unexpectedly empty for %q", nodeFQDN)
 	}
-	nodeFQDN = foundNode.Content.Host // the actual FQDN as returned by the cluster master, confusingly
+	nodeFQDN = foundNode.Host
 
-	// Re-create connection for node
-	conn, err = b.ensureNodeConnection(ctx, config, nodeFQDN)
+	// Create connection for node
+	conn, err := b.ensureNodeConnection(ctx, config, nodeFQDN)
 	if err != nil {
 		return nil, err
 	}
@@ -205,7 +224,7 @@ func (b *backend) credsReadHandlerMulti(ctx context.Context, req *logical.Reques
 		userPrefix = fmt.Sprintf("%s_%s", role.UserPrefix, req.DisplayName)
 	}
 	username := fmt.Sprintf("%s_%s", userPrefix, userUUID)
-	passwd, err := generateUserPassword(role)
+	passwd, err := b.generatePassword(ctx, role.PasswordSpec)
 	if err != nil {
 		return nil, fmt.Errorf("error generating new password: %w", err)
 	}
@@ -217,7 +236,8 @@ func (b *backend) credsReadHandlerMulti(ctx context.Context, req *logical.Reques
 		Email:      role.Email,
 		TZ:         role.TZ,
 	}
-	if _, _, err := conn.AccessControl.Authentication.Users.Create(&opts); err != nil {
+	walID, err := b.createUser(ctx, req.Storage, conn, name, role, nodeFQDN, &opts)
+	if err != nil {
 		return nil, err
 	}
 
@@ -225,7 +245,7 @@ func (b *backend) credsReadHandlerMulti(ctx context.Context, req *logical.Reques
 		// return to user
 		"username":   username,
 		"password":   passwd,
-		"roles":      role.Roles,
+		"roles":      opts.Roles,
 		"connection": role.Connection,
 		"url":        conn.Params().BaseURL,
 	}, map[string]interface{}{
@@ -236,12 +256,242 @@ func (b *backend) credsReadHandlerMulti(ctx context.Context, req *logical.Reques
 		"node_fqdn":  nodeFQDN,
 		"url":        conn.Params().BaseURL, // new in v0.7.0
 	})
+	if role.DynamicRole {
+		resp.Secret.InternalData["splunk_role"] = dynamicRoleName(username)
+	}
+	resp.Secret.TTL = role.DefaultTTL
+	resp.Secret.MaxTTL = role.MaxTTL
+
+	if err := framework.DeleteWAL(ctx, req.Storage, walID); err != nil {
+		// the rollback would delete the user while the lease is active
+		return nil, fmt.Errorf("error deleting WAL for user %q: %w", username, err)
+	}
+	return resp, nil
+}
+
+// credsReadHandlerAll creates the same user on all available nodes with allowed server roles, under a single lease.
+func (b *backend) credsReadHandlerAll(ctx context.Context, req *logical.Request, name string, role *roleConfig, config *splunkConfig,
+	nodes []discoveredNode, serverRole string) (*logical.Response, error) {
+	var matching []discoveredNode
+	for _, node := range nodes {
+		if node.Host == "" || !node.available() || !node.allowed(role) {
+			continue
+		}
+		if serverRole != "" && !strutil.StrListContains(node.ServerRoles, serverRole) {
+			continue
+		}
+		matching = append(matching, node)
+	}
+	if len(matching) == 0 {
+		return logical.ErrorResponse("no available nodes with allowed server roles for role %q", name), nil
+	}
+
+	// Generate credentials
+	userUUID, err := generateUserID(role)
+	if err != nil {
+		return nil, err
+	}
+	userPrefix := role.UserPrefix
+	if role.UserPrefix == defaultUserPrefix {
+		userPrefix = fmt.Sprintf("%s_%s", role.UserPrefix, req.DisplayName)
+	}
+	username := fmt.Sprintf("%s_%s", userPrefix, userUUID)
+	passwd, err := b.generatePassword(ctx, role.PasswordSpec)
+	if err != nil {
+		return nil, fmt.Errorf("error generating new password: %w", err)
+	}
+	opts := splunk.CreateUserOptions{
+		Name:       username,
+		Password:   passwd,
+		Roles:      role.Roles,
+		DefaultApp: role.DefaultApp,
+		Email:      role.Email,
+		TZ:         role.TZ,
+	}
+	walID, err := b.createUserOnNodes(ctx, req.Storage, config, name, role, matching, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := make([]string, 0, len(matching))
+	for _, node := range matching {
+		hosts = append(hosts, node.Host)
+	}
+	resp := b.Secret(secretCredsType).Response(map[string]interface{}{
+		// return to user
+		"username":   username,
+		"password":   passwd,
+		"roles":      opts.Roles,
+		"connection": role.Connection,
+		"node_fqdns": hosts,
+	}, map[string]interface{}{
+		// store (with lease)
+		"username":   username,
+		"role":       name,
+		"connection": role.Connection,
+		"node_fqdns": hosts,
+	})
+	if role.DynamicRole {
+		resp.Secret.InternalData["splunk_role"] = dynamicRoleName(username)
+	}
 	resp.Secret.TTL = role.DefaultTTL
 	resp.Secret.MaxTTL = role.MaxTTL
 
+	if err := framework.DeleteWAL(ctx, req.Storage, walID); err != nil {
+		// the rollback would delete the users while the lease is active
+		return nil, fmt.Errorf("error deleting WAL for user %q: %w", username, err)
+	}
 	return resp, nil
 }
 
+// createUserOnNodes creates the same Splunk user on all nodes in parallel, guarded by a single WAL entry, and
+// adds it to the user index.  If creating the user fails on any node, the users already created are deleted
+// again.  The caller must delete the WAL entry as for createUser.
+func (b *backend) createUserOnNodes(ctx context.Context, s logical.Storage, config *splunkConfig, roleName string, role *roleConfig,
+	nodes []discoveredNode, opts *splunk.CreateUserOptions) (string, error) {
+	hosts := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		hosts = append(hosts, node.Host)
+	}
+	walEntry := &walUser{
+		Connection: role.Connection,
+		NodeFQDNs:  hosts,
+		Username:   opts.Name,
+	}
+	if role.DynamicRole {
+		walEntry.SplunkRole = dynamicRoleName(opts.Name)
+	}
+	walID, err := framework.PutWAL(ctx, s, walTypeUser, walEntry)
+	if err != nil {
+		return "", fmt.Errorf("unable to create WAL for user %q: %w", opts.Name, err)
+	}
+
+	nodeOpts := make([]splunk.CreateUserOptions, len(nodes))
+	errs := make([]error, len(nodes))
+	var wg sync.WaitGroup
+	for i := range nodes {
+		nodeOpts[i] = *opts
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			conn, err := b.ensureDiscoveredNodeConnection(ctx, config, &nodes[i])
+			if err == nil {
+				_, err = createSplunkUser(ctx, conn, role, &nodeOpts[i])
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("node %q: %w", nodes[i].Host, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := joinErrors(errs); err != nil {
+		// users might have been created even on failing nodes
+		if cleanupErr := b.deleteUserOnNodes(ctx, config, hosts, opts.Name, walEntry.SplunkRole); cleanupErr != nil {
+			b.Logger().Warn("error deleting user after failure, leaving it to rollback", "username", opts.Name, "err", cleanupErr)
+		} else if cleanupErr := framework.DeleteWAL(ctx, s, walID); cleanupErr != nil {
+			b.Logger().Warn("error deleting WAL for user", "username", opts.Name, "err", cleanupErr)
+		}
+		return "", fmt.Errorf("error creating user %q: %w", opts.Name, err)
+	}
+	opts.Roles = nodeOpts[0].Roles
+
+	index := &userIndexEntry{
+		Role:       roleName,
+		NodeFQDNs:  hosts,
+		SplunkRole: walEntry.SplunkRole,
+		Expires:    time.Now().Add(b.leaseTTL(role)),
+	}
+	if err := index.store(ctx, s, role.Connection, opts.Name); err != nil {
+		return "", err
+	}
+	return walID, nil
+}
+
+// createUser creates a new Splunk user, guarded by a WAL entry, and adds it to the user index.
+// For roles with dynamic_role set, a new Splunk role is created for the user first, and added to opts.Roles.
+// The caller must delete the WAL entry once the credentials are about to be handed out; otherwise,
+// the WAL rollback deletes the user again.
+func (b *backend) createUser(ctx context.Context, s logical.Storage, conn *splunk.API, roleName string, role *roleConfig, nodeFQDN string, opts *splunk.CreateUserOptions) (string, error) {
+	walEntry := &walUser{
+		Connection: role.Connection,
+		NodeFQDN:   nodeFQDN,
+		Username:   opts.Name,
+	}
+	if role.DynamicRole {
+		walEntry.SplunkRole = dynamicRoleName(opts.Name)
+	}
+	walID, err := framework.PutWAL(ctx, s, walTypeUser, walEntry)
+	if err != nil {
+		return "", fmt.Errorf("unable to create WAL for user %q: %w", opts.Name, err)
+	}
+
+	splunkRole, err := createSplunkUser(ctx, conn, role, opts)
+	if err != nil {
+		// the user might have been created anyway, hence we leave the WAL in place
+		return "", err
+	}
+
+	index := &userIndexEntry{
+		Role:       roleName,
+		NodeFQDN:   nodeFQDN,
+		SplunkRole: splunkRole,
+		Expires:    time.Now().Add(b.leaseTTL(role)),
+	}
+	if err := index.store(ctx, s, role.Connection, opts.Name); err != nil {
+		return "", err
+	}
+	return walID, nil
+}
+
+// createSplunkUser creates the Splunk user opts.  For roles with dynamic_role set, a new Splunk role is
+// created for the user first, added to opts.Roles, and returned.
+func createSplunkUser(ctx context.Context, conn *splunk.API, role *roleConfig, opts *splunk.CreateUserOptions) (string, error) {
+	splunkRole := ""
+	if role.DynamicRole {
+		splunkRole = dynamicRoleName(opts.Name)
+		roleOpts := splunk.CreateRoleOptions{
+			Name: splunkRole,
+			RoleOptions: splunk.RoleOptions{
+				Capabilities:       role.Capabilities,
+				DefaultApp:         role.DefaultApp,
+				ImportedRoles:      role.ImportedRoles,
+				SrchFilter:         role.SearchFilter,
+				SrchIndexesAllowed: role.SearchIndexesAllowed,
+				SrchIndexesDefault: role.SearchIndexesDefault,
+			},
+		}
+		if _, _, err := conn.AccessControl.Authorization.Roles.Create(ctx, &roleOpts); err != nil {
+			return "", fmt.Errorf("error creating role %q: %w", splunkRole, err)
+		}
+		opts.Roles = append(append([]string{}, opts.Roles...), splunkRole)
+	}
+
+	if _, _, err := conn.AccessControl.Authentication.Users.Create(ctx, opts); err != nil {
+		return "", err
+	}
+	return splunkRole, nil
+}
+
+// dynamicRoleName returns the name of the Splunk role created for user.  Splunk role names must be lower-case.
+func dynamicRoleName(username string) string {
+	return strings.ToLower(username)
+}
+
+// leaseTTL returns the initial TTL of leases for role.
+func (b *backend) leaseTTL(role *roleConfig) time.Duration {
+	ttl := role.DefaultTTL
+	if ttl == 0 {
+		ttl = b.System().DefaultLeaseTTL()
+	}
+	if role.MaxTTL > 0 && ttl > role.MaxTTL {
+		ttl = role.MaxTTL
+	}
+	if maxTTL := b.System().MaxLeaseTTL(); maxTTL > 0 && ttl > maxTTL {
+		ttl = maxTTL
+	}
+	return ttl
+}
+
 func (b *backend) credsReadHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	name := d.Get("name").(string)
 	node_fqdn, present := d.GetOk("node_fqdn")
@@ -269,8 +519,11 @@ func generateUserID(roleConfig *roleConfig) (string, error) {
 	}
 }
 
-func generateUserPassword(roleConfig *roleConfig) (string, error) {
-	passwd, err := GeneratePassword(roleConfig.PasswordSpec)
+func (b *backend) generatePassword(ctx context.Context, spec *PasswordSpec) (string, error) {
+	if spec != nil && spec.Policy != "" {
+		return b.System().GeneratePasswordFromPolicy(ctx, spec.Policy)
+	}
+	passwd, err := GeneratePassword(spec)
 	if err == nil {
 		return passwd, nil
 	}
@@ -288,4 +541,8 @@ This path reads Splunk credentials for a certain role. The credentials
 will be generated on demand and will be automatically revoked when
 their lease expires.  Leases can be extended until a configured
 maximum life-time.
+
+For multi-node connections, node_fqdn "all" creates the same user on
+every available node with allowed server roles, optionally restricted
+by "server_role", under a single lease.
 `
@@ -1,11 +1,15 @@
 package splunk
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
+	"net/http"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/vault/sdk/logical"
 	"gotest.tools/v3/assert"
 
 	"github.com/splunk/vault-plugin-splunk/clients/splunk"
@@ -131,7 +135,7 @@ func Test_findNode(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := findNode(tt.args.nodeFQDN, tt.args.hosts, tt.args.roleConfig)
+			got, err := findNode(tt.args.nodeFQDN, nodesFromSearchPeers(tt.args.hosts), tt.args.roleConfig)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("findNode() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -142,3 +146,109 @@ func Test_findNode(t *testing.T) {
 		})
 	}
 }
+
+func Test_findNodeClusterPeers(t *testing.T) {
+	peers := make([]splunk.ClusterPeerEntry, 2)
+	peers[0].Content.Label = "idx1"
+	peers[0].Content.HostPortPair = "idx1.example.com:8089"
+	peers[0].Content.Status = "Up"
+	peers[0].Content.Site = "site1"
+	peers[1].Content.Label = "idx2"
+	peers[1].Content.HostPortPair = "idx2.example.com:8089"
+	peers[1].Content.Status = "Down"
+	nodes := nodesFromClusterPeers(peers)
+	role := &roleConfig{AllowedServerRoles: []string{"indexer"}}
+
+	node, err := findNode("IDX1", nodes, role)
+	assert.NilError(t, err)
+	assert.Equal(t, node.Host, "idx1.example.com")
+	assert.Equal(t, node.Site, "site1")
+	node, err = findNode("idx1.example.com", nodes, role)
+	assert.NilError(t, err)
+	assert.Equal(t, node.ServerName, "idx1")
+
+	_, err = findNode("idx2", nodes, role)
+	assert.ErrorContains(t, err, "not available")
+	_, err = findNode("idx1", nodes, &roleConfig{AllowedServerRoles: []string{"search_head"}})
+	assert.ErrorContains(t, err, "allowed server roles")
+}
+
+func Test_findNodeSHCMembers(t *testing.T) {
+	members := make([]splunk.SHCMemberEntry, 1)
+	members[0].Content.Label = "sh1"
+	members[0].Content.MgmtURI = "https://sh1.example.com:8089"
+	members[0].Content.Status = "Up"
+	nodes := nodesFromSHCMembers(members)
+
+	node, err := findNode("sh1", nodes, &roleConfig{AllowedServerRoles: []string{"search_head"}})
+	assert.NilError(t, err)
+	assert.Equal(t, node.Host, "sh1.example.com")
+}
+
+func TestBackend_createUserOnNodes(t *testing.T) {
+	ctx := context.Background()
+	nodes := []*testFakeNode{testNewFakeNode(t), testNewFakeNode(t), testNewFakeNode(t)}
+	b, storage := testFakeNodesBackend(t, nodes...)
+	config, err := connectionConfigLoad(ctx, storage, "testconn")
+	assert.NilError(t, err)
+	discovered, err := b.discoverNodes(ctx, config)
+	assert.NilError(t, err)
+	role := &roleConfig{Connection: "testconn", Roles: []string{"user"}}
+	create := func(username string) error {
+		_, err := b.createUserOnNodes(ctx, storage, config, "test", role, discovered, &splunk.CreateUserOptions{
+			Name:     username,
+			Password: "test1234",
+			Roles:    role.Roles,
+		})
+		return err
+	}
+
+	// users are created in parallel
+	for _, node := range nodes {
+		node.setDelay(100 * time.Millisecond)
+	}
+	start := time.Now()
+	assert.NilError(t, create("test_all"))
+	assert.Assert(t, time.Since(start) < 250*time.Millisecond, "took %s", time.Since(start))
+	for _, node := range nodes {
+		assert.Assert(t, node.hasUser("test_all"))
+		node.setDelay(0)
+	}
+	index, err := userIndexLoad(ctx, storage, "testconn", "test_all")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, index.NodeFQDNs, []string{"node0", "node1", "node2"})
+	assert.Equal(t, testCountWAL(t, storage, walTypeUser), 1)
+
+	// users created before a node failed are deleted again
+	nodes[2].setFail(http.MethodPost)
+	assert.ErrorContains(t, create("test_partial"), `node "node2"`)
+	for _, node := range nodes {
+		assert.Assert(t, !node.hasUser("test_partial"))
+	}
+	index, err = userIndexLoad(ctx, storage, "testconn", "test_partial")
+	assert.NilError(t, err)
+	assert.Assert(t, index == nil)
+	assert.Equal(t, testCountWAL(t, storage, walTypeUser), 1)
+
+	// if deleting fails as well, the WAL is kept for the rollback
+	nodes[0].setFail(http.MethodDelete)
+	assert.ErrorContains(t, create("test_cleanup"), `node "node2"`)
+	assert.Assert(t, nodes[0].hasUser("test_cleanup"))
+	assert.Assert(t, !nodes[1].hasUser("test_cleanup"))
+	assert.Equal(t, testCountWAL(t, storage, walTypeUser), 2)
+
+	nodes[0].setFail()
+	nodes[2].setFail()
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.RollbackOperation,
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"immediate": true,
+		},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, testCountWAL(t, storage, walTypeUser), 0)
+	assert.Assert(t, !nodes[0].hasUser("test_cleanup"))
+	// the first user was never handed out either
+	assert.Assert(t, !nodes[0].hasUser("test_all"))
+}
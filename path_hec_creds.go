@@ -0,0 +1,125 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/splunk/vault-plugin-splunk/clients/splunk"
+)
+
+const hecInputPrefix = "vault"
+
+func (b *backend) pathHECCreds() *framework.Path {
+	return &framework.Path{
+		Pattern: "hec-creds/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the HEC role",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: withIssueMetrics(secretHECCredsType, b.hecCredsReadHandler),
+		},
+
+		HelpSynopsis:    pathHECCredsHelpSyn,
+		HelpDescription: pathHECCredsHelpDesc,
+	}
+}
+
+func (b *backend) hecCredsReadHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	role, err := hecRoleConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse(fmt.Sprintf("HEC role not found: %q", name)), nil
+	}
+
+	config, err := connectionConfigLoad(ctx, req.Storage, role.Connection)
+	if err != nil {
+		return nil, err
+	}
+
+	// If role name isn't in allowed roles, send back a permission denied.
+	if !strutil.StrListContains(config.AllowedRoles, "*") && !strutil.StrListContainsGlob(config.AllowedRoles, name) {
+		return logical.ErrorResponse("%q is not an allowed role for connection %q", name, role.Connection), nil
+	}
+
+	conn, err := b.ensureConnection(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := GenerateShortUUID(8)
+	if err != nil {
+		return nil, err
+	}
+	inputName := fmt.Sprintf("%s_%s_%s", hecInputPrefix, name, id)
+
+	walID, err := framework.PutWAL(ctx, req.Storage, walTypeHEC, &walHEC{
+		Connection: role.Connection,
+		Name:       inputName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create WAL for HEC input %q: %w", inputName, err)
+	}
+	input, _, err := conn.HEC.Create(ctx, &splunk.CreateHECOptions{
+		Name:        inputName,
+		Description: fmt.Sprintf("Vault lease for HEC role %q", name),
+		Index:       role.Index,
+		Indexes:     role.AllowedIndexes,
+		Sourcetype:  role.Sourcetype,
+		Source:      role.Source,
+	})
+	if err != nil {
+		// the input might have been created anyway, hence we leave the WAL in place
+		return nil, fmt.Errorf("error creating HEC input %q: %w", inputName, err)
+	}
+	if input == nil || input.Content.Token == "" {
+		return nil, fmt.Errorf("no token returned for HEC input %q", inputName)
+	}
+
+	resp := b.Secret(secretHECCredsType).Response(map[string]interface{}{
+		// return to user
+		"token":           input.Content.Token,
+		"name":            inputName,
+		"index":           role.Index,
+		"allowed_indexes": role.AllowedIndexes,
+		"sourcetype":      role.Sourcetype,
+		"source":          role.Source,
+		"connection":      role.Connection,
+		"url":             conn.Params().BaseURL,
+	}, map[string]interface{}{
+		// store (with lease)
+		"name":       inputName,
+		"role":       name,
+		"connection": role.Connection,
+	})
+	resp.Secret.TTL = role.DefaultTTL
+	resp.Secret.MaxTTL = role.MaxTTL
+
+	if err := framework.DeleteWAL(ctx, req.Storage, walID); err != nil {
+		// the rollback would delete the input while the lease is active
+		return nil, fmt.Errorf("error deleting WAL for HEC input %q: %w", inputName, err)
+	}
+	return resp, nil
+}
+
+const pathHECCredsHelpSyn = `
+Request an HTTP Event Collector token for a certain HEC role.
+`
+
+const pathHECCredsHelpDesc = `
+This path creates a new HTTP Event Collector input as configured by
+a HEC role, and returns its token.  The input is deleted when the
+lease is revoked.  Leases can be extended until a configured maximum
+life-time.
+
+The HTTP Event Collector must be enabled in Splunk.
+`
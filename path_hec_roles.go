@@ -0,0 +1,174 @@
+package splunk
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func (b *backend) pathHECRoles() *framework.Path {
+	return &framework.Path{
+		Pattern: hecRolesPrefix + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the HEC role",
+			},
+			"connection": {
+				Type:        framework.TypeString,
+				Description: "Name of the Splunk connection this role acts on",
+			},
+			"default_ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Default TTL for role",
+			},
+			"max_ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Maximum time a token is valid for",
+			},
+			"index": {
+				Type:        framework.TypeString,
+				Description: "Default index for events sent with the token.",
+			},
+			"allowed_indexes": {
+				Type: framework.TypeCommaStringSlice,
+				Description: trimIndent(`
+				Comma-separated list of indexes events sent with the token may specify.  The
+				default index is always allowed.`),
+			},
+			"sourcetype": {
+				Type:        framework.TypeString,
+				Description: "Default sourcetype for events sent with the token.",
+			},
+			"source": {
+				Type:        framework.TypeString,
+				Description: "Default source for events sent with the token.",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation:   b.hecRolesReadHandler,
+			logical.CreateOperation: b.hecRolesWriteHandler,
+			logical.UpdateOperation: b.hecRolesWriteHandler,
+			logical.DeleteOperation: b.hecRolesDeleteHandler,
+		},
+		ExistenceCheck:  b.hecRolesExistenceCheckHandler,
+		HelpSynopsis:    pathHECRoleHelpSyn,
+		HelpDescription: pathHECRoleHelpDesc,
+	}
+}
+
+func (b *backend) hecRolesExistenceCheckHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (bool, error) {
+	name := d.Get("name").(string)
+	role, err := hecRoleConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return false, err
+	}
+	return role != nil, nil
+}
+
+func (b *backend) hecRolesReadHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	role, err := hecRoleConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, nil
+	}
+
+	resp := &logical.Response{
+		Data: role.toResponseData(),
+	}
+	return resp, nil
+}
+
+func (b *backend) hecRolesWriteHandler(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	role, err := hecRoleConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		role = &hecRoleConfig{}
+	}
+
+	if connRaw, ok := getValue(data, req.Operation, "connection"); ok {
+		role.Connection = connRaw.(string)
+	}
+	if role.Connection == "" {
+		return logical.ErrorResponse("empty Splunk connection name"), nil
+	}
+	if defaultTTLRaw, ok := getValue(data, req.Operation, "default_ttl"); ok {
+		role.DefaultTTL = time.Duration(defaultTTLRaw.(int)) * time.Second
+	}
+	if maxTTLRaw, ok := getValue(data, req.Operation, "max_ttl"); ok {
+		role.MaxTTL = time.Duration(maxTTLRaw.(int)) * time.Second
+	}
+	if indexRaw, ok := getValue(data, req.Operation, "index"); ok {
+		role.Index = indexRaw.(string)
+	}
+	if role.Index == "" {
+		return logical.ErrorResponse("index cannot be empty"), nil
+	}
+	if allowedIndexesRaw, ok := getValue(data, req.Operation, "allowed_indexes"); ok {
+		role.AllowedIndexes = allowedIndexesRaw.([]string)
+	}
+	if !strutil.StrListContains(role.AllowedIndexes, role.Index) {
+		role.AllowedIndexes = append(role.AllowedIndexes, role.Index)
+	}
+	if sourcetypeRaw, ok := getValue(data, req.Operation, "sourcetype"); ok {
+		role.Sourcetype = sourcetypeRaw.(string)
+	}
+	if sourceRaw, ok := getValue(data, req.Operation, "source"); ok {
+		role.Source = sourceRaw.(string)
+	}
+
+	if err := role.store(ctx, req.Storage, name); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (b *backend) hecRolesDeleteHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	if err := req.Storage.Delete(ctx, hecRolesPrefix+name); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (b *backend) pathHECRolesList() *framework.Path {
+	return &framework.Path{
+		Pattern: hecRolesPrefix + "?$",
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.hecRolesListHandler,
+		},
+		HelpSynopsis:    pathHECRoleHelpSyn,
+		HelpDescription: pathHECRoleHelpDesc,
+	}
+}
+
+func (b *backend) hecRolesListHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	entries, err := req.Storage.List(ctx, hecRolesPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(entries), nil
+}
+
+const pathHECRoleHelpSyn = `
+Manage the roles for HTTP Event Collector tokens.
+`
+
+const pathHECRoleHelpDesc = `
+This path lets you manage HEC roles, which define the HTTP Event
+Collector inputs created via "hec-creds/": the default index, the
+indexes allowed, and the default sourcetype and source of events.
+
+See the documentation for hec-roles/name for a full list of accepted
+parameters.
+`
@@ -10,8 +10,9 @@ import (
 )
 
 const (
-	rolesPrefix       = "roles/"
-	defaultUserPrefix = "vault"
+	rolesPrefix          = "roles/"
+	defaultUserPrefix    = "vault"
+	defaultTokenAudience = "vault"
 
 	userIDSchemeUUID4_v0_5_0 = ""
 	userIDSchemeUUID4        = "uuid4"
@@ -20,7 +21,7 @@ const (
 )
 
 func (b *backend) pathRoles() *framework.Path {
-	return &framework.Path{
+	p := &framework.Path{
 		Pattern: rolesPrefix + framework.GenericNameRegex("name"),
 		Fields: map[string]*framework.FieldSchema{
 			"name": {
@@ -75,6 +76,45 @@ func (b *backend) pathRoles() *framework.Path {
 					userIDSchemeUUID4, userIDSchemeBase58_64, userIDSchemeBase58_128, userIDSchemeBase58_64),
 				Default: userIDSchemeBase58_64,
 			},
+			"dynamic_role": {
+				Type: framework.TypeBool,
+				Description: trimIndent(`
+				Whether to create a new Splunk role for each lease, which is deleted together
+				with the user.  The role is configured with "capabilities", "imported_roles",
+				"search_indexes_allowed", "search_indexes_default" and "search_filter".
+				Default: false`),
+			},
+			"capabilities": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Comma-separated list of capabilities of the dynamic Splunk role.",
+			},
+			"imported_roles": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Comma-separated list of Splunk roles the dynamic Splunk role inherits from.",
+			},
+			"search_indexes_allowed": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Comma-separated list of indexes the dynamic Splunk role may search.",
+			},
+			"search_indexes_default": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Comma-separated list of indexes the dynamic Splunk role searches by default.",
+			},
+			"search_filter": {
+				Type:        framework.TypeString,
+				Description: "Search filter applied to all searches of the dynamic Splunk role.",
+			},
+			"token_user": {
+				Type: framework.TypeString,
+				Description: trimIndent(`
+				Existing Splunk user to issue authentication tokens for via "tokens/".  If
+				empty, the role cannot issue tokens.`),
+			},
+			"token_audience": {
+				Type:        framework.TypeString,
+				Description: fmt.Sprintf("Audience of issued authentication tokens.  Default: %q", defaultTokenAudience),
+				Default:     defaultTokenAudience,
+			},
 		},
 		Callbacks: map[logical.Operation]framework.OperationFunc{
 			logical.ReadOperation:   b.rolesReadHandler,
@@ -86,6 +126,53 @@ func (b *backend) pathRoles() *framework.Path {
 		HelpSynopsis:    pathRoleHelpSyn,
 		HelpDescription: pathRoleHelpDesc,
 	}
+	addPasswordSpecFields(p.Fields)
+	return p
+}
+
+// addPasswordSpecFields adds the fields for configuring password generation to fields.
+func addPasswordSpecFields(fields map[string]*framework.FieldSchema) {
+	passwordFields := map[string]*framework.FieldSchema{
+		"password_length": {
+			Type:        framework.TypeInt,
+			Description: fmt.Sprintf("Length of generated passwords.  Must be at least %d.  Default: 32", minPasswordLength),
+			Default:     32,
+		},
+		"password_num_digits": {
+			Type:        framework.TypeInt,
+			Description: "Number of digits in generated passwords.  Default: 4",
+			Default:     4,
+		},
+		"password_num_symbols": {
+			Type:        framework.TypeInt,
+			Description: "Number of symbols in generated passwords.  Default: 4",
+			Default:     4,
+		},
+		"password_symbols": {
+			Type:        framework.TypeString,
+			Description: fmt.Sprintf("Symbols to choose from for generated passwords.  Default: %q", DefaultPasswordSymbols),
+			Default:     DefaultPasswordSymbols,
+		},
+		"password_allow_upper": {
+			Type:        framework.TypeBool,
+			Description: "Whether generated passwords may contain upper-case letters.  Default: true",
+			Default:     true,
+		},
+		"password_allow_repeat": {
+			Type:        framework.TypeBool,
+			Description: "Whether generated passwords may contain repeated characters.  Default: true",
+			Default:     true,
+		},
+		"password_policy": {
+			Type: framework.TypeString,
+			Description: trimIndent(`
+			Name of a Vault password policy for generating passwords.  If set, all other
+			password_* settings are ignored.`),
+		},
+	}
+	for k, v := range passwordFields {
+		fields[k] = v
+	}
 }
 
 // Returning 'true' forces an UpdateOperation, CreateOperation otherwise.
@@ -139,12 +226,45 @@ func (b *backend) rolesWriteHandler(ctx context.Context, req *logical.Request, d
 	if allowedServerRoles, ok := getValue(data, req.Operation, "allowed_server_roles"); ok {
 		role.AllowedServerRoles = allowedServerRoles.([]string)
 	}
-	role.PasswordSpec = DefaultPasswordSpec() // XXX make configurable
+	if role.PasswordSpec == nil {
+		role.PasswordSpec = DefaultPasswordSpec()
+	}
+	if resp, err := b.updatePasswordSpec(ctx, role.PasswordSpec, data, req.Operation); resp != nil || err != nil {
+		return resp, err
+	}
 
 	if roles, ok := getValue(data, req.Operation, "roles"); ok {
 		role.Roles = roles.([]string)
 	}
-	if len(role.Roles) == 0 {
+	if dynamicRoleRaw, ok := getValue(data, req.Operation, "dynamic_role"); ok {
+		role.DynamicRole = dynamicRoleRaw.(bool)
+	}
+	if capabilitiesRaw, ok := getValue(data, req.Operation, "capabilities"); ok {
+		role.Capabilities = capabilitiesRaw.([]string)
+	}
+	if importedRolesRaw, ok := getValue(data, req.Operation, "imported_roles"); ok {
+		role.ImportedRoles = importedRolesRaw.([]string)
+	}
+	if searchIndexesAllowedRaw, ok := getValue(data, req.Operation, "search_indexes_allowed"); ok {
+		role.SearchIndexesAllowed = searchIndexesAllowedRaw.([]string)
+	}
+	if searchIndexesDefaultRaw, ok := getValue(data, req.Operation, "search_indexes_default"); ok {
+		role.SearchIndexesDefault = searchIndexesDefaultRaw.([]string)
+	}
+	if searchFilterRaw, ok := getValue(data, req.Operation, "search_filter"); ok {
+		role.SearchFilter = searchFilterRaw.(string)
+	}
+	if role.DynamicRole && len(role.Capabilities) == 0 && len(role.ImportedRoles) == 0 {
+		return logical.ErrorResponse("dynamic_role requires capabilities or imported_roles"), nil
+	}
+
+	if tokenUserRaw, ok := getValue(data, req.Operation, "token_user"); ok {
+		role.TokenUser = tokenUserRaw.(string)
+	}
+	if tokenAudienceRaw, ok := getValue(data, req.Operation, "token_audience"); ok {
+		role.TokenAudience = tokenAudienceRaw.(string)
+	}
+	if len(role.Roles) == 0 && !role.DynamicRole && role.TokenUser == "" {
 		return logical.ErrorResponse("roles cannot be empty"), nil
 	}
 	if defaultAppRaw, ok := getValue(data, req.Operation, "default_app"); ok {
@@ -181,6 +301,41 @@ func (b *backend) rolesWriteHandler(ctx context.Context, req *logical.Request, d
 	return nil, nil
 }
 
+// updatePasswordSpec applies the password_* fields to spec, and validates the result.
+func (b *backend) updatePasswordSpec(ctx context.Context, spec *PasswordSpec, data *framework.FieldData, op logical.Operation) (*logical.Response, error) {
+	if lengthRaw, ok := getValue(data, op, "password_length"); ok {
+		spec.Length = lengthRaw.(int)
+	}
+	if numDigitsRaw, ok := getValue(data, op, "password_num_digits"); ok {
+		spec.NumDigits = numDigitsRaw.(int)
+	}
+	if numSymbolsRaw, ok := getValue(data, op, "password_num_symbols"); ok {
+		spec.NumSymbols = numSymbolsRaw.(int)
+	}
+	if symbolsRaw, ok := getValue(data, op, "password_symbols"); ok {
+		spec.Symbols = symbolsRaw.(string)
+	}
+	if allowUpperRaw, ok := getValue(data, op, "password_allow_upper"); ok {
+		spec.AllowUpper = allowUpperRaw.(bool)
+	}
+	if allowRepeatRaw, ok := getValue(data, op, "password_allow_repeat"); ok {
+		spec.AllowRepeat = allowRepeatRaw.(bool)
+	}
+	if policyRaw, ok := getValue(data, op, "password_policy"); ok {
+		spec.Policy = policyRaw.(string)
+	}
+
+	if err := spec.Validate(); err != nil {
+		return logical.ErrorResponse("invalid password settings: %s", err), nil
+	}
+	if spec.Policy != "" {
+		if _, err := b.System().GeneratePasswordFromPolicy(ctx, spec.Policy); err != nil {
+			return logical.ErrorResponse("invalid password_policy %q: %s", spec.Policy, err), nil
+		}
+	}
+	return nil, nil
+}
+
 func (b *backend) rolesDeleteHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	name := d.Get("name").(string)
 	if err := req.Storage.Delete(ctx, rolesPrefix+name); err != nil {
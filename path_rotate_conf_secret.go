@@ -0,0 +1,80 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func (b *backend) pathRotateConfSecret() *framework.Path {
+	return &framework.Path{
+		Pattern: "rotate-conf-secret/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the conf secret",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.rotateConfSecretUpdateHandler,
+		},
+
+		HelpSynopsis:    pathRotateConfSecretHelpSyn,
+		HelpDescription: pathRotateConfSecretHelpDesc,
+	}
+}
+
+func (b *backend) rotateConfSecretUpdateHandler(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.confSecretLock.Lock()
+	defer b.confSecretLock.Unlock()
+
+	name := data.Get("name").(string)
+	secret, err := confSecretConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return logical.ErrorResponse(fmt.Sprintf("conf secret not found: %q", name)), nil
+	}
+
+	if err := b.rotateSecret(ctx, req.Storage, confSecretSecrets, name, secret); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (secret *confSecretConfig) location() string {
+	return fmt.Sprintf("%s.conf [%s] %s", secret.File, secret.Stanza, secret.Key)
+}
+
+// sameLocation returns true if both conf secrets are written to the same key on the same nodes.
+func (secret *confSecretConfig) sameLocation(other *confSecretConfig) bool {
+	return secret.Connection == other.Connection && secret.File == other.File && secret.Stanza == other.Stanza &&
+		secret.Key == other.Key && strutil.EquivalentSlices(secret.NodeFQDNs, other.NodeFQDNs)
+}
+
+// apply writes value to all nodes of a conf secret.
+func (secret *confSecretConfig) apply(ctx context.Context, b *backend, config *splunkConfig, value string) error {
+	for _, nodeFQDN := range secret.nodes() {
+		conn, err := b.ensureNodeConnection(ctx, config, nodeFQDN)
+		if err != nil {
+			return err
+		}
+		if _, _, err := conn.Properties.UpdateKey(ctx, secret.File, secret.Stanza, secret.Key, value); err != nil {
+			return fmt.Errorf("error updating %s on node %q: %w", secret.location(), nodeFQDN, err)
+		}
+	}
+	return nil
+}
+
+const pathRotateConfSecretHelpSyn = `
+Request to rotate a secret in a Splunk configuration file.
+`
+
+const pathRotateConfSecretHelpDesc = `
+This path writes a new value for the given conf secret to all its nodes
+immediately, independent of its rotation period.
+`
@@ -0,0 +1,84 @@
+package splunk
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"gotest.tools/v3/assert"
+)
+
+const testConfSecretProperty = "server/general/pass4SymmKey"
+
+func testRollback(t *testing.T, b *backend, s logical.Storage) {
+	t.Helper()
+	_, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.RollbackOperation,
+		Storage:   s,
+		Data: map[string]interface{}{
+			"immediate": true,
+		},
+	})
+	assert.NilError(t, err)
+}
+
+func TestBackend_rotateConfSecretPartialFailure(t *testing.T) {
+	ctx := context.Background()
+	node0, node1 := testNewFakeNode(t), testNewFakeNode(t)
+	b, storage := testFakeNodesBackend(t, node0, node1)
+	secret := func() *confSecretConfig {
+		return &confSecretConfig{
+			Connection:     "testconn",
+			File:           "server",
+			Stanza:         "general",
+			Key:            "pass4SymmKey",
+			NodeFQDNs:      []string{"node0", "node1"},
+			RotationPeriod: time.Hour,
+			PasswordSpec:   DefaultPasswordSpec(),
+		}
+	}
+
+	// a new secret, written to the first node only: the rollback completes the rotation
+	node1.setFail(http.MethodPost)
+	err := b.rotateSecret(ctx, storage, confSecretSecrets, "symmkey", secret())
+	assert.ErrorContains(t, err, "injected failure")
+	written := node0.property(testConfSecretProperty)
+	assert.Assert(t, written != "")
+	assert.Equal(t, node1.property(testConfSecretProperty), "")
+	stored, err := confSecretConfigLoad(ctx, storage, "symmkey")
+	assert.NilError(t, err)
+	assert.Assert(t, stored == nil)
+
+	node1.setFail()
+	testRollback(t, b, storage)
+	assert.Equal(t, testCountWAL(t, storage, walTypeConfSecret), 0)
+	assert.Equal(t, node1.property(testConfSecretProperty), written)
+	stored, err = confSecretConfigLoad(ctx, storage, "symmkey")
+	assert.NilError(t, err)
+	assert.Equal(t, stored.Value, written)
+	assert.DeepEqual(t, stored.NodeFQDNs, []string{"node0", "node1"})
+	assert.Equal(t, stored.RotationPeriod, time.Hour)
+	assert.DeepEqual(t, stored.PasswordSpec, DefaultPasswordSpec())
+
+	// rotation of a stored secret: the rollback resets all nodes to the stored value
+	node1.setFail(http.MethodPost)
+	err = b.rotateSecret(ctx, storage, confSecretSecrets, "symmkey", stored)
+	assert.ErrorContains(t, err, "injected failure")
+	assert.Assert(t, node0.property(testConfSecretProperty) != written)
+
+	node1.setFail()
+	testRollback(t, b, storage)
+	assert.Equal(t, testCountWAL(t, storage, walTypeConfSecret), 0)
+	assert.Equal(t, node0.property(testConfSecretProperty), written)
+	assert.Equal(t, node1.property(testConfSecretProperty), written)
+}
+
+func TestBackend_rotationRollbackNoConnection(t *testing.T) {
+	node := testNewFakeNode(t)
+	b, storage := testFakeNodesBackend(t, node)
+	err := b.rotationRollback(context.Background(), &logical.Request{Storage: storage}, confSecretSecrets,
+		map[string]interface{}{"Name": "symmkey", "Secret": "{}", "Value": "secret"})
+	assert.Error(t, err, `conf secret WAL entry for "symmkey" has no connection`)
+}
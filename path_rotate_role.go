@@ -0,0 +1,55 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func (b *backend) pathRotateRole() *framework.Path {
+	return &framework.Path{
+		Pattern: "rotate-role/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the static role",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.rotateRoleUpdateHandler,
+		},
+
+		HelpSynopsis:    pathRotateRoleHelpSyn,
+		HelpDescription: pathRotateRoleHelpDesc,
+	}
+}
+
+func (b *backend) rotateRoleUpdateHandler(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.staticRoleLock.Lock()
+	defer b.staticRoleLock.Unlock()
+
+	name := data.Get("name").(string)
+	role, err := staticRoleConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse(fmt.Sprintf("static role not found: %q", name)), nil
+	}
+
+	if err := b.rotateSecret(ctx, req.Storage, staticRoleSecrets, name, role); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+const pathRotateRoleHelpSyn = `
+Request to rotate the password of a static role.
+`
+
+const pathRotateRoleHelpDesc = `
+This path rotates the password of the Splunk user managed by the given
+static role immediately, independent of its rotation period.
+`
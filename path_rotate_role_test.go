@@ -0,0 +1,70 @@
+package splunk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBackend_rotateStaticRoleStoreFailure(t *testing.T) {
+	ctx := context.Background()
+	node := testNewFakeNode(t)
+	node.addUser("svc")
+	node.addUser("svc2")
+	b, s := testFakeNodesBackend(t, node)
+	storage := &testFailingStorage{Storage: s}
+	role := func(username string) *staticRoleConfig {
+		return &staticRoleConfig{
+			Connection:     "testconn",
+			Username:       username,
+			RotationPeriod: time.Hour,
+			PasswordSpec:   DefaultPasswordSpec(),
+		}
+	}
+
+	// a new role, whose password was set but not stored: the rollback completes the rotation
+	storage.failPutPrefix = staticRolesPrefix
+	err := b.rotateSecret(ctx, storage, staticRoleSecrets, "svc", role("svc"))
+	assert.ErrorContains(t, err, "injected storage failure")
+	password := node.password("svc")
+	assert.Assert(t, password != "")
+
+	storage.failPutPrefix = ""
+	testRollback(t, b, storage)
+	assert.Equal(t, testCountWAL(t, storage, walTypeStaticRole), 0)
+	stored, err := staticRoleConfigLoad(ctx, storage, "svc")
+	assert.NilError(t, err)
+	assert.Equal(t, stored.Password, password)
+	assert.Equal(t, stored.RotationPeriod, time.Hour)
+	assert.DeepEqual(t, stored.PasswordSpec, DefaultPasswordSpec())
+	assert.Equal(t, node.password("svc"), password)
+
+	// rotation of a stored role: the rollback resets the password to the stored one
+	storage.failPutPrefix = staticRolesPrefix
+	err = b.rotateSecret(ctx, storage, staticRoleSecrets, "svc", stored)
+	assert.ErrorContains(t, err, "injected storage failure")
+	assert.Assert(t, node.password("svc") != password)
+
+	storage.failPutPrefix = ""
+	testRollback(t, b, storage)
+	assert.Equal(t, testCountWAL(t, storage, walTypeStaticRole), 0)
+	assert.Equal(t, node.password("svc"), password)
+
+	// a failed move to another user, superseded by a successful rotation: the rollback keeps the stored role
+	storage.failPutPrefix = staticRolesPrefix
+	err = b.rotateSecret(ctx, storage, staticRoleSecrets, "svc", role("svc2"))
+	assert.ErrorContains(t, err, "injected storage failure")
+	storage.failPutPrefix = ""
+	stored, err = staticRoleConfigLoad(ctx, storage, "svc")
+	assert.NilError(t, err)
+	assert.NilError(t, b.rotateSecret(ctx, storage, staticRoleSecrets, "svc", stored))
+
+	testRollback(t, b, storage)
+	assert.Equal(t, testCountWAL(t, storage, walTypeStaticRole), 0)
+	stored, err = staticRoleConfigLoad(ctx, storage, "svc")
+	assert.NilError(t, err)
+	assert.Equal(t, stored.Username, "svc")
+	assert.Equal(t, node.password("svc"), stored.Password)
+}
@@ -3,6 +3,7 @@ package splunk
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault/sdk/framework"
@@ -29,8 +30,26 @@ func (b *backend) pathRotateRoot() *framework.Path {
 }
 
 func (b *backend) rotateRootUpdateHandler(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
 	name := data.Get("name").(string)
-	oldConfig, err := connectionConfigLoad(ctx, req.Storage, name)
+	config, err := b.rotateRoot(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &logical.Response{
+		Data: config.toMinimalResponseData(),
+	}
+	return resp, nil
+}
+
+// rotateRoot sets a new password for the admin user of a connection, and stores the configuration.
+//
+// The caller must hold configLock.
+func (b *backend) rotateRoot(ctx context.Context, s logical.Storage, name string) (*splunkConfig, error) {
+	oldConfig, err := connectionConfigLoad(ctx, s, name)
 	if err != nil {
 		return nil, err
 	}
@@ -38,6 +57,9 @@ func (b *backend) rotateRootUpdateHandler(ctx context.Context, req *logical.Requ
 	if err != nil {
 		return nil, err
 	}
+	if oldConfig.Token != "" {
+		return b.rotateRootToken(ctx, s, name, oldConfig, conn)
+	}
 
 	config := *oldConfig
 	passwd, err := uuid.GenerateUUID()
@@ -51,19 +73,101 @@ func (b *backend) rotateRootUpdateHandler(ctx context.Context, req *logical.Requ
 		Password:    config.Password,
 	}
 
-	// XXX write WAL in case we restart between successful update and store
-	if _, _, err := conn.AccessControl.Authentication.Users.Update(config.Username, &opts); err != nil {
+	// in case we restart, or fail to store the configuration after a successful update,
+	// the WAL rollback will find out which password is valid.
+	walID, err := framework.PutWAL(ctx, s, walTypeRoot, &walRoot{
+		Name:        name,
+		Username:    config.Username,
+		OldPassword: oldConfig.Password,
+		NewPassword: config.Password,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create WAL for rotating root credentials: %w", err)
+	}
+
+	if _, _, err := conn.AccessControl.Authentication.Users.Update(ctx, config.Username, &opts); err != nil {
+		// the outcome of the update is unknown, hence we leave the WAL in place
 		return nil, fmt.Errorf("error updating password: %w", err)
 	}
 
-	if err := config.store(ctx, req.Storage, name); err != nil {
+	if err := config.store(ctx, s, name); err != nil {
 		return nil, err
 	}
+	if err := framework.DeleteWAL(ctx, s, walID); err != nil {
+		// rollback will find that the new password is already stored, so this is harmless
+		b.Logger().Warn("error deleting WAL for root rotation", "connection", name, "err", err)
+	}
 
-	resp := &logical.Response{
-		Data: config.toMinimalResponseData(),
+	state, err := rootRotationStateLoad(ctx, s, name)
+	if err != nil {
+		return nil, err
 	}
-	return resp, nil
+	state.succeeded(&config, time.Now())
+	if err := state.store(ctx, s, name); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// rotateRootToken issues a new token for the admin user of a connection, stores the configuration, and
+// revokes the old token.
+//
+// The caller must hold configLock.
+func (b *backend) rotateRootToken(ctx context.Context, s logical.Storage, name string, oldConfig *splunkConfig, conn *splunk.API) (*splunkConfig, error) {
+	token, _, err := conn.AccessControl.Authorization.Tokens.Create(ctx, &splunk.CreateTokenOptions{
+		Name:     oldConfig.Username,
+		Audience: defaultTokenAudience,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating token for user %q: %w", oldConfig.Username, err)
+	}
+	if token == nil || token.Content.ID == "" {
+		return nil, fmt.Errorf("no token returned for user %q", oldConfig.Username)
+	}
+
+	// the WAL rollback revokes the new token unless it was stored, or the old one otherwise
+	walID, err := framework.PutWAL(ctx, s, walTypeRootToken, &walRootToken{
+		Name:       name,
+		Username:   oldConfig.Username,
+		OldTokenID: oldConfig.TokenID,
+		NewTokenID: token.Content.ID,
+	})
+	if err != nil {
+		if _, err := conn.AccessControl.Authorization.Tokens.Delete(ctx, oldConfig.Username, token.Content.ID); err != nil {
+			b.Logger().Error("error deleting unused token", "connection", name, "token_id", token.Content.ID, "err", err)
+		}
+		return nil, fmt.Errorf("unable to create WAL for rotating root token: %w", err)
+	}
+
+	config := *oldConfig
+	config.Token = token.Content.Token
+	config.TokenID = token.Content.ID
+	if err := config.store(ctx, s, name); err != nil {
+		return nil, err
+	}
+
+	if oldConfig.TokenID == "" {
+		b.Logger().Warn("token_id not configured, unable to revoke old root token", "connection", name)
+	} else if _, err := conn.AccessControl.Authorization.Tokens.Delete(ctx, oldConfig.Username, oldConfig.TokenID); err != nil {
+		// the WAL rollback retries
+		b.Logger().Warn("error revoking old root token", "connection", name, "token_id", oldConfig.TokenID, "err", err)
+		walID = ""
+	}
+	if walID != "" {
+		if err := framework.DeleteWAL(ctx, s, walID); err != nil {
+			b.Logger().Warn("error deleting WAL for root token rotation", "connection", name, "err", err)
+		}
+	}
+
+	state, err := rootRotationStateLoad(ctx, s, name)
+	if err != nil {
+		return nil, err
+	}
+	state.succeeded(&config, time.Now())
+	if err := state.store(ctx, s, name); err != nil {
+		return nil, err
+	}
+	return &config, nil
 }
 
 const pathRotateRootHelpSyn = `
@@ -72,4 +176,10 @@ Request to rotate the Splunk credentials for a Splunk connection.
 
 const pathRotateRootHelpDesc = `
 This path attempts to rotate the root credentials for the given Splunk connection.
+
+For connections authenticating with a token, a new token is issued for the
+admin user, and the old one is revoked if "token_id" is known.
+
+Root credentials can also be rotated automatically by setting "rotation_period"
+on the connection.
 `
@@ -0,0 +1,75 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func (b *backend) pathStaticCreds() *framework.Path {
+	return &framework.Path{
+		Pattern: "static-creds/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the static role",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.staticCredsReadHandler,
+		},
+
+		HelpSynopsis:    pathStaticCredsHelpSyn,
+		HelpDescription: pathStaticCredsHelpDesc,
+	}
+}
+
+func (b *backend) staticCredsReadHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	role, err := staticRoleConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse(fmt.Sprintf("static role not found: %q", name)), nil
+	}
+
+	config, err := connectionConfigLoad(ctx, req.Storage, role.Connection)
+	if err != nil {
+		return nil, err
+	}
+	if !strutil.StrListContains(config.AllowedRoles, "*") && !strutil.StrListContainsGlob(config.AllowedRoles, name) {
+		return logical.ErrorResponse("%q is not an allowed role for connection %q", name, role.Connection), nil
+	}
+
+	nextRotation := role.NextRotation()
+	ttl := rotationTTL(role)
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"username":            role.Username,
+			"password":            role.Password,
+			"connection":          role.Connection,
+			"url":                 config.URL,
+			"rotation_period":     int64(role.RotationPeriod.Seconds()),
+			"ttl":                 int64(ttl.Seconds()),
+			"last_vault_rotation": role.LastRotated,
+			"next_vault_rotation": nextRotation,
+		},
+	}
+	return resp, nil
+}
+
+// #nosec G101
+const pathStaticCredsHelpSyn = `
+Request the current Splunk credentials for a static role.
+`
+
+const pathStaticCredsHelpDesc = `
+This path reads the current password of the Splunk user managed by a
+static role, along with the times of the last and the next password
+rotation.
+`
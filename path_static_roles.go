@@ -0,0 +1,181 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func (b *backend) pathStaticRoles() *framework.Path {
+	p := &framework.Path{
+		Pattern: staticRolesPrefix + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the static role",
+			},
+			"connection": {
+				Type:        framework.TypeString,
+				Description: "Name of the Splunk connection this role acts on",
+			},
+			"username": {
+				Type:        framework.TypeString,
+				Description: "Existing Splunk user whose password is managed by this role",
+			},
+			"rotation_period": {
+				Type: framework.TypeDurationSecond,
+				Description: fmt.Sprintf("Period for automatic password rotation.  Must be at least %s.",
+					minRotationPeriod),
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation:   b.staticRolesReadHandler,
+			logical.CreateOperation: b.staticRolesWriteHandler,
+			logical.UpdateOperation: b.staticRolesWriteHandler,
+			logical.DeleteOperation: b.staticRolesDeleteHandler,
+		},
+		ExistenceCheck:  b.staticRolesExistenceCheckHandler,
+		HelpSynopsis:    pathStaticRoleHelpSyn,
+		HelpDescription: pathStaticRoleHelpDesc,
+	}
+	addPasswordSpecFields(p.Fields)
+	return p
+}
+
+func (b *backend) staticRolesExistenceCheckHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (bool, error) {
+	name := d.Get("name").(string)
+	role, err := staticRoleConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return false, err
+	}
+	return role != nil, nil
+}
+
+func (b *backend) staticRolesReadHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	role, err := staticRoleConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, nil
+	}
+
+	resp := &logical.Response{
+		Data: role.toResponseData(),
+	}
+	return resp, nil
+}
+
+func (b *backend) staticRolesWriteHandler(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.staticRoleLock.Lock()
+	defer b.staticRoleLock.Unlock()
+
+	name := data.Get("name").(string)
+	role, err := staticRoleConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		role = &staticRoleConfig{}
+	}
+	oldRole := *role
+
+	if connRaw, ok := getValue(data, req.Operation, "connection"); ok {
+		role.Connection = connRaw.(string)
+	}
+	if role.Connection == "" {
+		return logical.ErrorResponse("empty Splunk connection name"), nil
+	}
+	if usernameRaw, ok := getValue(data, req.Operation, "username"); ok {
+		role.Username = usernameRaw.(string)
+	}
+	if role.Username == "" {
+		return logical.ErrorResponse("empty username"), nil
+	}
+	if rotationPeriodRaw, ok := getValue(data, req.Operation, "rotation_period"); ok {
+		role.RotationPeriod = time.Duration(rotationPeriodRaw.(int)) * time.Second
+	}
+	if role.RotationPeriod < minRotationPeriod {
+		return logical.ErrorResponse("rotation_period must be at least %s", minRotationPeriod), nil
+	}
+	if role.PasswordSpec == nil {
+		role.PasswordSpec = DefaultPasswordSpec()
+	}
+	if resp, err := b.updatePasswordSpec(ctx, role.PasswordSpec, data, req.Operation); resp != nil || err != nil {
+		return resp, err
+	}
+
+	config, err := connectionConfigLoad(ctx, req.Storage, role.Connection)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if !strutil.StrListContains(config.AllowedRoles, "*") && !strutil.StrListContainsGlob(config.AllowedRoles, name) {
+		return logical.ErrorResponse("%q is not an allowed role for connection %q", name, role.Connection), nil
+	}
+	if strings.EqualFold(role.Username, config.Username) {
+		return logical.ErrorResponse("user %q is the admin user of connection %q; use rotate-root instead", role.Username, role.Connection), nil
+	}
+
+	// a new user, or a user on a different connection needs a password managed by Vault
+	if req.Operation == logical.CreateOperation || role.Connection != oldRole.Connection || role.Username != oldRole.Username {
+		if err := b.rotateSecret(ctx, req.Storage, staticRoleSecrets, name, role); err != nil {
+			return logical.ErrorResponse("error setting initial password for user %q: %s", role.Username, err), nil
+		}
+		return nil, nil
+	}
+
+	if err := role.store(ctx, req.Storage, name); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (b *backend) staticRolesDeleteHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	b.staticRoleLock.Lock()
+	defer b.staticRoleLock.Unlock()
+
+	name := d.Get("name").(string)
+	if err := req.Storage.Delete(ctx, staticRolesPrefix+name); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (b *backend) pathStaticRolesList() *framework.Path {
+	return &framework.Path{
+		Pattern: staticRolesPrefix + "?$",
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.staticRolesListHandler,
+		},
+		HelpSynopsis:    pathStaticRoleHelpSyn,
+		HelpDescription: pathStaticRoleHelpDesc,
+	}
+}
+
+func (b *backend) staticRolesListHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	entries, err := req.Storage.List(ctx, staticRolesPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(entries), nil
+}
+
+const pathStaticRoleHelpSyn = `
+Manage the static roles for existing Splunk users.
+`
+
+const pathStaticRoleHelpDesc = `
+This path lets you manage static roles, which bind an existing Splunk
+user to a connection.  Vault takes over the user's password: it is
+rotated when the role is created, and then every "rotation_period".
+
+See the documentation for static-roles/name for a full list of accepted
+parameters.
+`
@@ -0,0 +1,118 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func (b *backend) pathTidy() *framework.Path {
+	return &framework.Path{
+		Pattern: "tidy/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of this Splunk connection",
+			},
+			"dry_run": {
+				Type:        framework.TypeBool,
+				Default:     false,
+				Description: "Only report which users would be deleted.  Default: false",
+			},
+			"safety_buffer": {
+				Type: framework.TypeDurationSecond,
+				Description: trimIndent(`
+				Time that a user must have been without a lease before it is deleted.
+				Default: the tidy_safety_buffer of the connection`),
+			},
+			"user_prefix": {
+				Type: framework.TypeCommaStringSlice,
+				Description: trimIndent(`
+				Comma-separated list of user name prefixes to consider.  Default: the
+				user_prefix of all roles using this connection`),
+			},
+			"node_fqdn": {
+				Type:        framework.TypeString,
+				Description: "Node to tidy for multi-node connections.  Default: all search peers",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.tidyUpdateHandler,
+		},
+
+		HelpSynopsis:    pathTidyHelpSyn,
+		HelpDescription: pathTidyHelpDesc,
+	}
+}
+
+func (b *backend) tidyUpdateHandler(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.tidyLock.Lock()
+	defer b.tidyLock.Unlock()
+
+	name := data.Get("name").(string)
+	config, err := connectionConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &tidyOptions{
+		UserPrefixes: data.Get("user_prefix").([]string),
+		NodeFQDN:     data.Get("node_fqdn").(string),
+		SafetyBuffer: config.tidySafetyBuffer(),
+		DryRun:       data.Get("dry_run").(bool),
+	}
+	if safetyBufferRaw, ok := data.GetOk("safety_buffer"); ok {
+		opts.SafetyBuffer = time.Duration(safetyBufferRaw.(int)) * time.Second
+	}
+	if opts.SafetyBuffer < 0 {
+		return logical.ErrorResponse("safety_buffer cannot be negative"), nil
+	}
+	if config.IsStandalone && opts.NodeFQDN != "" {
+		return logical.ErrorResponse("node_fqdn not supported for standalone connection %q", name), nil
+	}
+
+	result, err := b.tidyUsers(ctx, req.Storage, name, config, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"deleted":       result.Deleted,
+			"pending":       result.Pending,
+			"dry_run":       opts.DryRun,
+			"safety_buffer": int64(opts.SafetyBuffer.Seconds()),
+		},
+	}
+	for _, e := range result.Errors {
+		resp.AddWarning(fmt.Sprintf("error during tidy: %s", e))
+	}
+	return resp, nil
+}
+
+const pathTidyHelpSyn = `
+Delete orphaned Splunk users created by Vault.
+`
+
+const pathTidyHelpDesc = `
+This path lists the Splunk users created by Vault for the given
+connection, and deletes those that have been without a lease for longer
+than the safety buffer.  Such users are left behind by failed
+revocations, restored Vault snapshots, or connections deleted while
+leases were still active.
+
+Users of the connection's admin and of static roles are never deleted.
+
+A user unknown to Vault is only deleted if earlier tidy runs have seen it
+for longer than both the safety buffer and the max TTL of the mount and
+of the roles using this connection.  Leases issued by older versions of
+this plugin are only known to Vault once they are renewed.  With
+"dry_run", users are reported in "deleted" without actually deleting
+them, and no state is recorded.  Users still within the safety buffer are
+reported in "pending".
+
+If "tidy_period" is set for the connection, tidy also runs periodically.
+`
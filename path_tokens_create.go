@@ -0,0 +1,110 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/splunk/vault-plugin-splunk/clients/splunk"
+)
+
+func (b *backend) pathTokensCreate() *framework.Path {
+	return &framework.Path{
+		Pattern: "tokens/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the role",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: withIssueMetrics(secretTokenType, b.tokensReadHandler),
+		},
+
+		HelpSynopsis:    pathTokensCreateHelpSyn,
+		HelpDescription: pathTokensCreateHelpDesc,
+	}
+}
+
+func (b *backend) tokensReadHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	role, err := roleConfigLoad(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse(fmt.Sprintf("role not found: %q", name)), nil
+	}
+	if role.TokenUser == "" {
+		return logical.ErrorResponse("token_user not configured for role %q", name), nil
+	}
+
+	config, err := connectionConfigLoad(ctx, req.Storage, role.Connection)
+	if err != nil {
+		return nil, err
+	}
+
+	// If role name isn't in allowed roles, send back a permission denied.
+	if !strutil.StrListContains(config.AllowedRoles, "*") && !strutil.StrListContainsGlob(config.AllowedRoles, name) {
+		return logical.ErrorResponse("%q is not an allowed role for connection %q", name, role.Connection), nil
+	}
+
+	conn, err := b.ensureConnection(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// tokens cannot be extended, hence the lease is not renewable beyond the token expiry
+	ttl := b.leaseTTL(role)
+	expiresOn := time.Now().Add(ttl)
+	token, _, err := conn.AccessControl.Authorization.Tokens.Create(ctx, &splunk.CreateTokenOptions{
+		Name:      role.TokenUser,
+		Audience:  role.TokenAudience,
+		ExpiresOn: fmt.Sprintf("+%ds", int64(ttl.Seconds())),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating token for user %q: %w", role.TokenUser, err)
+	}
+	if token == nil || token.Content.ID == "" {
+		return nil, fmt.Errorf("no token returned for user %q", role.TokenUser)
+	}
+
+	resp := b.Secret(secretTokenType).Response(map[string]interface{}{
+		// return to user
+		"token":      token.Content.Token,
+		"token_id":   token.Content.ID,
+		"username":   role.TokenUser,
+		"audience":   role.TokenAudience,
+		"expires_on": expiresOn,
+		"connection": role.Connection,
+		"url":        conn.Params().BaseURL,
+	}, map[string]interface{}{
+		// store (with lease)
+		"token_id":   token.Content.ID,
+		"username":   role.TokenUser,
+		"role":       name,
+		"connection": role.Connection,
+	})
+	resp.Secret.TTL = ttl
+	resp.Secret.MaxTTL = ttl
+	resp.Secret.Renewable = false
+	return resp, nil
+}
+
+const pathTokensCreateHelpSyn = `
+Request a Splunk authentication token for a certain role.
+`
+
+const pathTokensCreateHelpDesc = `
+This path issues a Splunk authentication token for the "token_user" of
+a certain role.  The token expires together with its lease, and is
+deleted from Splunk when the lease is revoked.  Leases cannot be
+extended, since the expiry of tokens is fixed.
+
+Authentication tokens require Splunk 7.3 or later, with token
+authentication enabled.
+`
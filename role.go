@@ -23,6 +23,18 @@ type roleConfig struct {
 	TZ           string   `json:"tz,omitempty" structs:"tz"`
 	UserPrefix   string   `json:"user_prefix,omitempty" structs:"user_prefix"`
 	UserIDScheme string   `json:"user_id_scheme,omitempty" structs:"user_id_scheme"`
+
+	// Splunk role created for each lease
+	DynamicRole          bool     `json:"dynamic_role,omitempty" structs:"dynamic_role"`
+	Capabilities         []string `json:"capabilities,omitempty" structs:"capabilities"`
+	ImportedRoles        []string `json:"imported_roles,omitempty" structs:"imported_roles"`
+	SearchIndexesAllowed []string `json:"search_indexes_allowed,omitempty" structs:"search_indexes_allowed"`
+	SearchIndexesDefault []string `json:"search_indexes_default,omitempty" structs:"search_indexes_default"`
+	SearchFilter         string   `json:"search_filter,omitempty" structs:"search_filter"`
+
+	// Splunk authentication tokens
+	TokenUser     string `json:"token_user,omitempty" structs:"token_user"`
+	TokenAudience string `json:"token_audience,omitempty" structs:"token_audience"`
 }
 
 // Role returns nil if role named `name` does not exist in `storage`, otherwise
@@ -63,5 +75,22 @@ func (role *roleConfig) toResponseData() map[string]interface{} {
 	// need to patch up TTLs because time.Duration gets garbled
 	data["default_ttl"] = int64(role.DefaultTTL.Seconds())
 	data["max_ttl"] = int64(role.MaxTTL.Seconds())
+
+	delete(data, "password_spec")
+	addPasswordSpecResponseData(data, role.PasswordSpec)
 	return data
 }
+
+// addPasswordSpecResponseData flattens password settings into data, so that they match the request fields.
+func addPasswordSpecResponseData(data map[string]interface{}, spec *PasswordSpec) {
+	if spec == nil {
+		return
+	}
+	data["password_length"] = spec.Length
+	data["password_num_digits"] = spec.NumDigits
+	data["password_num_symbols"] = spec.NumSymbols
+	data["password_symbols"] = spec.symbols()
+	data["password_allow_upper"] = spec.AllowUpper
+	data["password_allow_repeat"] = spec.AllowRepeat
+	data["password_policy"] = spec.Policy
+}
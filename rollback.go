@@ -2,15 +2,24 @@ package splunk
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/mitchellh/mapstructure"
+
+	"github.com/splunk/vault-plugin-splunk/clients/splunk"
 )
 
 const (
 	walTypeConn       = "connection"
+	walTypeStaticRole = "static-role"
+	walTypeRoot       = "root"
+	walTypeRootToken  = "root-token"
+	walTypeUser       = "user"
+	walTypeHEC        = "hec"
+	walTypeConfSecret = "conf-secret"
 	walRollbackMinAge = 5 * time.Minute
 )
 
@@ -18,10 +27,81 @@ type walConnection struct {
 	ID string
 }
 
+// walRoot records a pending rotation of root credentials.  WAL entries are seal-wrapped.
+type walRoot struct {
+	Name        string
+	Username    string
+	OldPassword string
+	NewPassword string
+}
+
+// walRootToken records a pending rotation of the root token.
+type walRootToken struct {
+	Name       string
+	Username   string
+	OldTokenID string
+	NewTokenID string
+}
+
+// walUser records a Splunk user, which is deleted unless the credentials are handed out.
+// SplunkRole is the dynamic Splunk role of the user, if any, which is deleted as well.
+// NodeFQDNs is set instead of NodeFQDN for users created on several nodes.
+type walUser struct {
+	Connection string
+	NodeFQDN   string
+	NodeFQDNs  []string
+	Username   string
+	SplunkRole string
+}
+
+func (entry *walUser) nodes() []string {
+	if len(entry.NodeFQDNs) > 0 {
+		return entry.NodeFQDNs
+	}
+	return []string{entry.NodeFQDN}
+}
+
+// walHEC records an HTTP Event Collector input, which is deleted unless the token is handed out.
+type walHEC struct {
+	Connection string
+	Name       string
+}
+
+// walRotation records a pending rotation of a static role password or a conf secret.  The secret about to be
+// stored is recorded as JSON, since new secrets, and secrets moved to another target, are only stored after the
+// rotation.  PreviousRotation is the last rotation of the stored secret, see rotationStamp.
+type walRotation struct {
+	Name             string
+	Secret           string
+	Value            string
+	PreviousRotation int64
+}
+
+// rotationStamp returns the time of the last rotation of a stored secret in WAL entries, which cannot hold
+// a time.Time.  It is zero for secrets that were never rotated.
+func rotationStamp(lastRotated time.Time) int64 {
+	if lastRotated.IsZero() {
+		return 0
+	}
+	return lastRotated.UnixNano()
+}
+
 func (b *backend) walRollback(ctx context.Context, req *logical.Request, kind string, data interface{}) error {
 	switch kind {
 	case walTypeConn:
 		return b.connectionRollback(ctx, req, data)
+	case walTypeStaticRole:
+		return b.rotationRollback(ctx, req, staticRoleSecrets, data)
+	case walTypeRoot:
+		return b.rootRollback(ctx, req, data)
+	case walTypeRootToken:
+		return b.rootTokenRollback(ctx, req, data)
+	case walTypeUser:
+		return b.userRollback(ctx, req, data)
+	case walTypeHEC:
+		return b.hecRollback(ctx, req, data)
+	case walTypeConfSecret:
+		return b.rotationRollback(ctx, req, confSecretSecrets, data)
 	default:
 		return fmt.Errorf("unknown type to rollback")
 	}
@@ -40,3 +120,220 @@ func (b *backend) connectionRollback(ctx context.Context, req *logical.Request,
 	}
 	return nil
 }
+
+// rootRollback completes an interrupted rotation of root credentials.
+//
+// If Splunk accepts the new password, it is stored.  Otherwise, the update never happened,
+// and the stored old password is still valid.
+func (b *backend) rootRollback(ctx context.Context, req *logical.Request, data interface{}) error {
+	var entry walRoot
+	if err := mapstructure.Decode(data, &entry); err != nil {
+		return err
+	}
+
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
+	exists, err := connectionConfigExists(ctx, req.Storage, entry.Name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	config, err := connectionConfigLoad(ctx, req.Storage, entry.Name)
+	if err != nil {
+		return err
+	}
+	if config.Username != entry.Username || config.Password != entry.OldPassword {
+		// rotation was completed, or the configuration was changed since
+		return nil
+	}
+
+	newConfig := *config
+	newConfig.Password = entry.NewPassword
+	if err := newConfig.checkLogin(ctx); err == nil {
+		b.Logger().Info("completing interrupted root rotation", "connection", entry.Name)
+		if err := newConfig.store(ctx, req.Storage, entry.Name); err != nil {
+			return err
+		}
+		state, err := rootRotationStateLoad(ctx, req.Storage, entry.Name)
+		if err != nil {
+			return err
+		}
+		state.succeeded(&newConfig, time.Now())
+		return state.store(ctx, req.Storage, entry.Name)
+	}
+
+	if err := config.checkLogin(ctx); err != nil {
+		return fmt.Errorf("unable to log in to connection %q with either the old or new root password: %w", entry.Name, err)
+	}
+	// update never happened
+	return nil
+}
+
+// rootTokenRollback revokes the token that is not in use after an interrupted rotation of the root token.
+func (b *backend) rootTokenRollback(ctx context.Context, req *logical.Request, data interface{}) error {
+	var entry walRootToken
+	if err := mapstructure.Decode(data, &entry); err != nil {
+		return err
+	}
+
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
+	exists, err := connectionConfigExists(ctx, req.Storage, entry.Name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	config, err := connectionConfigLoad(ctx, req.Storage, entry.Name)
+	if err != nil {
+		return err
+	}
+	unused := entry.NewTokenID
+	if config.TokenID == entry.NewTokenID {
+		unused = entry.OldTokenID
+	}
+	if unused == "" {
+		return nil
+	}
+
+	conn, err := b.ensureConnection(ctx, config)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.AccessControl.Authorization.Tokens.Delete(ctx, entry.Username, unused); err != nil && !splunk.IsNotFound(err) {
+		return fmt.Errorf("error revoking unused root token of connection %q: %w", entry.Name, err)
+	}
+	return nil
+}
+
+// userRollback deletes a Splunk user, whose credentials were never handed out.
+func (b *backend) userRollback(ctx context.Context, req *logical.Request, data interface{}) error {
+	var entry walUser
+	if err := mapstructure.Decode(data, &entry); err != nil {
+		return err
+	}
+
+	exists, err := connectionConfigExists(ctx, req.Storage, entry.Connection)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		b.Logger().Warn("connection not found, unable to delete orphaned user",
+			"connection", entry.Connection, "nodeFQDN", entry.NodeFQDN, "username", entry.Username)
+		return nil
+	}
+	config, err := connectionConfigLoad(ctx, req.Storage, entry.Connection)
+	if err != nil {
+		return err
+	}
+	for _, nodeFQDN := range entry.nodes() {
+		conn, err := b.ensureNodeConnection(ctx, config, nodeFQDN)
+		if err != nil {
+			return err
+		}
+		b.Logger().Info("deleting orphaned user", "connection", entry.Connection, "nodeFQDN", nodeFQDN, "username", entry.Username)
+		if err := deleteSplunkUser(ctx, conn, entry.Username, entry.SplunkRole); err != nil {
+			return err
+		}
+	}
+	// the user might have been added to the index before the failure
+	return userIndexDelete(ctx, req.Storage, entry.Connection, entry.Username)
+}
+
+// rotationRollback makes Splunk and Vault agree on the value of a static role password or conf secret, after
+// an interrupted rotation.
+//
+// If the rotation was for the target of the stored secret, Splunk is reset to the stored value.  Otherwise,
+// the secret is new or was moved to another target, and the previous value in Splunk is unknown.  The
+// rotation is then completed by setting the new value again and storing the secret.  Nothing is done if
+// the stored secret was rotated since.
+func (b *backend) rotationRollback(ctx context.Context, req *logical.Request, kind *rotatedSecretKind, data interface{}) error {
+	var entry walRotation
+	if err := mapstructure.Decode(data, &entry); err != nil {
+		return err
+	}
+	target := kind.new()
+	if err := json.Unmarshal([]byte(entry.Secret), target); err != nil {
+		return fmt.Errorf("error decoding %s WAL entry: %w", kind.name, err)
+	}
+	if target.connectionName() == "" {
+		return fmt.Errorf("%s WAL entry for %q has no connection", kind.name, entry.Name)
+	}
+
+	lock := kind.lock(b)
+	lock.Lock()
+	defer lock.Unlock()
+
+	stored, err := kind.load(ctx, req.Storage, entry.Name)
+	if err != nil {
+		return err
+	}
+	if stored != nil && rotationStamp(stored.lastRotated()) != entry.PreviousRotation {
+		// rotation was completed, or superseded
+		return nil
+	}
+	value := entry.Value
+	if stored != nil && stored.current() != "" && stored.sameTarget(target) {
+		target, value = stored, stored.current()
+	}
+
+	exists, err := connectionConfigExists(ctx, req.Storage, target.connectionName())
+	if err != nil {
+		return err
+	}
+	if !exists {
+		b.Logger().Warn("connection not found, unable to roll back "+kind.name+" rotation",
+			"connection", target.connectionName(), "name", entry.Name)
+		return nil
+	}
+	config, err := connectionConfigLoad(ctx, req.Storage, target.connectionName())
+	if err != nil {
+		return err
+	}
+	if err := target.apply(ctx, b, config, value); err != nil {
+		return err
+	}
+	if target == stored {
+		return nil
+	}
+	b.Logger().Info("completing interrupted "+kind.name+" rotation", "name", entry.Name)
+	target.rotated(value, time.Now())
+	return target.store(ctx, req.Storage, entry.Name)
+}
+
+// hecRollback deletes an HTTP Event Collector input, whose token was never handed out.
+func (b *backend) hecRollback(ctx context.Context, req *logical.Request, data interface{}) error {
+	var entry walHEC
+	if err := mapstructure.Decode(data, &entry); err != nil {
+		return err
+	}
+
+	exists, err := connectionConfigExists(ctx, req.Storage, entry.Connection)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		b.Logger().Warn("connection not found, unable to delete orphaned HEC input",
+			"connection", entry.Connection, "name", entry.Name)
+		return nil
+	}
+	config, err := connectionConfigLoad(ctx, req.Storage, entry.Connection)
+	if err != nil {
+		return err
+	}
+	conn, err := b.ensureConnection(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	b.Logger().Info("deleting orphaned HEC input", "connection", entry.Connection, "name", entry.Name)
+	if _, err := conn.HEC.Delete(ctx, entry.Name); err != nil && !splunk.IsNotFound(err) {
+		return fmt.Errorf("error deleting HEC input %q: %w", entry.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	rootRotationPrefix = "root-rotation/"
+
+	rootRotationBackoffBase = time.Minute
+	rootRotationBackoffMax  = time.Hour
+)
+
+// rootRotationState tracks scheduled rotations of the admin password of a connection.
+//
+// It is stored separately from the connection configuration, since storing the latter
+// invalidates cached connections.
+type rootRotationState struct {
+	LastRotated  time.Time `json:"last_rotated"`
+	NextRotation time.Time `json:"next_rotation"`
+
+	// failed scheduled rotations since the last success
+	Failures    int       `json:"failures"`
+	LastError   string    `json:"last_error,omitempty"`
+	NextAttempt time.Time `json:"next_attempt"`
+}
+
+func rootRotationStateLoad(ctx context.Context, s logical.Storage, name string) (*rootRotationState, error) {
+	entry, err := s.Get(ctx, rootRotationPrefix+name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading root rotation state: %w", err)
+	}
+	state := rootRotationState{}
+	if entry == nil {
+		return &state, nil
+	}
+	if err := entry.DecodeJSON(&state); err != nil {
+		return nil, fmt.Errorf("error decoding root rotation state: %w", err)
+	}
+	return &state, nil
+}
+
+func (state *rootRotationState) store(ctx context.Context, s logical.Storage, name string) error {
+	entry, err := logical.StorageEntryJSON(rootRotationPrefix+name, state)
+	if err != nil {
+		return err
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return fmt.Errorf("error writing %q JSON: %w", rootRotationPrefix+name, err)
+	}
+	return nil
+}
+
+// schedule computes the next rotation time according to the configured rotation period.
+func (state *rootRotationState) schedule(config *splunkConfig, now time.Time) {
+	if config.RotationPeriod == 0 {
+		state.NextRotation = time.Time{}
+		return
+	}
+	base := state.LastRotated
+	if base.IsZero() {
+		base = now
+	}
+	state.NextRotation = base.Add(config.RotationPeriod)
+}
+
+// succeeded records a successful rotation, and schedules the next one.
+func (state *rootRotationState) succeeded(config *splunkConfig, now time.Time) {
+	state.LastRotated = now
+	state.Failures = 0
+	state.LastError = ""
+	state.NextAttempt = time.Time{}
+	state.schedule(config, now)
+}
+
+// failed records a failed scheduled rotation, and backs off exponentially before the next attempt.
+func (state *rootRotationState) failed(err error, now time.Time) {
+	state.Failures++
+	state.LastError = err.Error()
+
+	backoff := rootRotationBackoffMax
+	if shift := state.Failures - 1; shift < 6 {
+		backoff = rootRotationBackoffBase << shift
+	}
+	if backoff > rootRotationBackoffMax {
+		backoff = rootRotationBackoffMax
+	}
+	state.NextAttempt = now.Add(backoff)
+}
+
+// reschedule skips a missed rotation, and schedules the next one within the rotation period.  Failures of
+// the skipped rotation are forgotten.
+func (state *rootRotationState) reschedule(config *splunkConfig, now time.Time) {
+	for !state.NextRotation.After(now) {
+		state.NextRotation = state.NextRotation.Add(config.RotationPeriod)
+	}
+	state.Failures = 0
+	state.LastError = ""
+	state.NextAttempt = time.Time{}
+}
+
+// due returns true if a scheduled rotation should be attempted now.
+func (state *rootRotationState) due(now time.Time) bool {
+	return !state.NextRotation.IsZero() && !now.Before(state.NextRotation) && !now.Before(state.NextAttempt)
+}
+
+// missedWindow returns true if the scheduled rotation can no longer happen within the rotation window.
+func (state *rootRotationState) missedWindow(config *splunkConfig, now time.Time) bool {
+	return config.RotationWindow > 0 && now.After(state.NextRotation.Add(config.RotationWindow))
+}
+
+func (state *rootRotationState) addResponseData(data map[string]interface{}) {
+	if !state.LastRotated.IsZero() {
+		data["last_rotated"] = state.LastRotated
+	}
+	if !state.NextRotation.IsZero() {
+		data["next_rotation"] = state.NextRotation
+	}
+	if state.Failures > 0 {
+		data["rotation_failures"] = state.Failures
+		data["rotation_last_error"] = state.LastError
+		data["rotation_next_attempt"] = state.NextAttempt
+	}
+}
+
+// rotateExpiredRoots rotates the admin passwords of all connections that are due.
+func (b *backend) rotateExpiredRoots(ctx context.Context, s logical.Storage) error {
+	names, err := s.List(ctx, "config/")
+	if err != nil {
+		return fmt.Errorf("error listing connections: %w", err)
+	}
+
+	var failed []string
+	for _, name := range names {
+		if strings.HasSuffix(name, "/") {
+			continue
+		}
+		if err := b.rotateExpiredRoot(ctx, s, name); err != nil {
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("error rotating root credentials for connections: %q", failed)
+	}
+	return nil
+}
+
+// rotateExpiredRoot rotates the admin password of a connection if it is due.  An error is returned if
+// the rotation failed.
+//
+// configLock is only held for a single connection, so that an unresponsive Splunk instance does not
+// block the configuration of all other connections.
+func (b *backend) rotateExpiredRoot(ctx context.Context, s logical.Storage, name string) error {
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
+	config, err := connectionConfigLoad(ctx, s, name)
+	if err != nil {
+		// e.g., deleted since listing
+		b.Logger().Error("error loading connection for root rotation", "connection", name, "err", err)
+		return nil
+	}
+	if config.RotationPeriod == 0 {
+		return nil
+	}
+	state, err := rootRotationStateLoad(ctx, s, name)
+	if err != nil {
+		b.Logger().Error("error loading root rotation state", "connection", name, "err", err)
+		return nil
+	}
+
+	now := time.Now()
+	if state.NextRotation.IsZero() {
+		// e.g., configured by an earlier version
+		state.schedule(config, now)
+		if err := state.store(ctx, s, name); err != nil {
+			b.Logger().Error("error scheduling root rotation", "connection", name, "err", err)
+		}
+		return nil
+	}
+	if !state.due(now) {
+		return nil
+	}
+	if state.missedWindow(config, now) {
+		b.Logger().Warn("missed root rotation window, rescheduling", "connection", name,
+			"scheduled", state.NextRotation, "window", config.RotationWindow)
+		state.reschedule(config, now)
+		if err := state.store(ctx, s, name); err != nil {
+			b.Logger().Error("error rescheduling root rotation", "connection", name, "err", err)
+		}
+		return nil
+	}
+
+	b.Logger().Info("rotating root credentials", "connection", name)
+	if _, err := b.rotateRoot(ctx, s, name); err != nil {
+		state.failed(err, now)
+		b.Logger().Error("error rotating root credentials", "connection", name,
+			"failures", state.Failures, "next_attempt", state.NextAttempt, "err", err)
+		if err := state.store(ctx, s, name); err != nil {
+			b.Logger().Error("error storing root rotation state", "connection", name, "err", err)
+		}
+		return err
+	}
+	return nil
+}
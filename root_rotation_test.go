@@ -0,0 +1,90 @@
+package splunk
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRootRotationState_schedule(t *testing.T) {
+	now := time.Now()
+	config := &splunkConfig{RotationPeriod: 24 * time.Hour}
+
+	state := &rootRotationState{}
+	state.schedule(config, now)
+	assert.Equal(t, state.NextRotation, now.Add(24*time.Hour))
+	assert.Assert(t, !state.due(now))
+	assert.Assert(t, state.due(now.Add(24*time.Hour)))
+
+	state.LastRotated = now.Add(-48 * time.Hour)
+	state.schedule(config, now)
+	assert.Assert(t, state.due(now))
+
+	config.RotationPeriod = 0
+	state.schedule(config, now)
+	assert.Assert(t, state.NextRotation.IsZero())
+	assert.Assert(t, !state.due(now))
+}
+
+func TestRootRotationState_failed(t *testing.T) {
+	now := time.Now()
+	config := &splunkConfig{RotationPeriod: 24 * time.Hour}
+	state := &rootRotationState{}
+	state.schedule(config, now.Add(-25*time.Hour))
+
+	expected := []time.Duration{
+		time.Minute,
+		2 * time.Minute,
+		4 * time.Minute,
+		8 * time.Minute,
+		16 * time.Minute,
+		32 * time.Minute,
+		time.Hour,
+		time.Hour,
+	}
+	for i, backoff := range expected {
+		state.failed(fmt.Errorf("failure %d", i), now)
+		assert.Equal(t, state.Failures, i+1)
+		assert.Equal(t, state.NextAttempt, now.Add(backoff))
+		assert.Assert(t, !state.due(now))
+		assert.Assert(t, state.due(now.Add(backoff)))
+	}
+	assert.Equal(t, state.LastError, "failure 7")
+
+	state.succeeded(config, now)
+	assert.Equal(t, state.Failures, 0)
+	assert.Equal(t, state.LastError, "")
+	assert.Equal(t, state.LastRotated, now)
+	assert.Equal(t, state.NextRotation, now.Add(24*time.Hour))
+}
+
+func TestRootRotationState_missedWindow(t *testing.T) {
+	now := time.Now()
+	config := &splunkConfig{RotationPeriod: 24 * time.Hour}
+	state := &rootRotationState{NextRotation: now.Add(-2 * time.Hour)}
+
+	assert.Assert(t, !state.missedWindow(config, now))
+	config.RotationWindow = 3 * time.Hour
+	assert.Assert(t, !state.missedWindow(config, now))
+	config.RotationWindow = time.Hour
+	assert.Assert(t, state.missedWindow(config, now))
+}
+
+func TestRootRotationState_reschedule(t *testing.T) {
+	now := time.Now()
+	config := &splunkConfig{RotationPeriod: 24 * time.Hour, RotationWindow: time.Hour}
+	scheduled := now.Add(-50 * time.Hour)
+	state := &rootRotationState{NextRotation: scheduled}
+	state.failed(fmt.Errorf("connection refused"), now.Add(-49*time.Hour))
+
+	state.reschedule(config, now)
+	assert.Equal(t, state.NextRotation, scheduled.Add(72*time.Hour))
+	assert.Equal(t, state.Failures, 0)
+	assert.Equal(t, state.LastError, "")
+	assert.Assert(t, state.NextAttempt.IsZero())
+	data := map[string]interface{}{}
+	state.addResponseData(data)
+	assert.Assert(t, data["rotation_failures"] == nil)
+}
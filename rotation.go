@@ -0,0 +1,136 @@
+package splunk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// rotatedSecret is a secret that Vault generates and rotates every rotation period: the password of a static
+// role, or a conf secret.  Scheduling, rotation and rollback are shared; implementations provide the parts
+// specific to how they are stored and set in Splunk.
+type rotatedSecret interface {
+	// NextRotation returns the time of the next scheduled rotation.
+	NextRotation() time.Time
+
+	connectionName() string
+	passwordSpec() *PasswordSpec
+	// current returns the value stored in Vault, empty if there is none yet.
+	current() string
+	lastRotated() time.Time
+	// rotated records value as the current one.
+	rotated(value string, now time.Time)
+	// sameTarget returns true if other is set at the same place in Splunk.
+	sameTarget(other rotatedSecret) bool
+	// apply sets value in Splunk.
+	apply(ctx context.Context, b *backend, config *splunkConfig, value string) error
+	store(ctx context.Context, s logical.Storage, name string) error
+}
+
+// rotatedSecretKind describes where secrets of one kind are stored, and how rotations are serialized.
+type rotatedSecretKind struct {
+	// for messages, e.g., "static role"
+	name    string
+	prefix  string
+	walType string
+	lock    func(b *backend) *sync.Mutex
+	// load returns nil if the secret does not exist.
+	load func(ctx context.Context, s logical.Storage, name string) (rotatedSecret, error)
+	// new returns an empty secret to decode WAL entries into.
+	new func() rotatedSecret
+}
+
+// rotationTTL returns the time until the next rotation of secret.
+func rotationTTL(secret rotatedSecret) time.Duration {
+	ttl := time.Until(secret.NextRotation())
+	if ttl < 0 {
+		// rotation is overdue, and will happen soon
+		ttl = 0
+	}
+	return ttl
+}
+
+// rotateSecret sets a new value for secret in Splunk, and stores the secret.
+//
+// A WAL entry guards against failures between updating Splunk and storing the new value.
+// If the entry is not deleted, the rollback resets Splunk to the stored value, or completes
+// the rotation if the secret is not stored for this target yet (see rotationRollback).
+//
+// The caller must hold the lock of kind.
+func (b *backend) rotateSecret(ctx context.Context, s logical.Storage, kind *rotatedSecretKind, name string, secret rotatedSecret) error {
+	config, err := connectionConfigLoad(ctx, s, secret.connectionName())
+	if err != nil {
+		return err
+	}
+
+	value, err := b.generatePassword(ctx, secret.passwordSpec())
+	if err != nil {
+		return fmt.Errorf("error generating new value: %w", err)
+	}
+
+	encoded, err := json.Marshal(secret)
+	if err != nil {
+		return err
+	}
+	walID, err := framework.PutWAL(ctx, s, kind.walType, &walRotation{
+		Name:             name,
+		Secret:           string(encoded),
+		Value:            value,
+		PreviousRotation: rotationStamp(secret.lastRotated()),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create WAL for rotating %s: %w", kind.name, err)
+	}
+
+	if err := secret.apply(ctx, b, config, value); err != nil {
+		// Splunk might have been updated, at least partially, hence we leave the WAL in place
+		return err
+	}
+
+	secret.rotated(value, time.Now())
+	if err := secret.store(ctx, s, name); err != nil {
+		return err
+	}
+
+	if err := framework.DeleteWAL(ctx, s, walID); err != nil {
+		// rollback finds the secret rotated since, so this is harmless
+		b.Logger().Warn("error deleting WAL for "+kind.name, "name", name, "err", err)
+	}
+	return nil
+}
+
+// rotateExpiredSecrets rotates all secrets of kind that are due.
+func (b *backend) rotateExpiredSecrets(ctx context.Context, s logical.Storage, kind *rotatedSecretKind) error {
+	lock := kind.lock(b)
+	lock.Lock()
+	defer lock.Unlock()
+
+	names, err := s.List(ctx, kind.prefix)
+	if err != nil {
+		return fmt.Errorf("error listing %ss: %w", kind.name, err)
+	}
+
+	var failed []string
+	for _, name := range names {
+		secret, err := kind.load(ctx, s, name)
+		if err != nil || secret == nil {
+			continue
+		}
+		if time.Now().Before(secret.NextRotation()) {
+			continue
+		}
+		if err := b.rotateSecret(ctx, s, kind, name, secret); err != nil {
+			b.Logger().Error("error rotating "+kind.name, "name", name, "err", err)
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("error rotating %ss: %q", kind.name, failed)
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package splunk
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
@@ -17,8 +19,8 @@ func (b *backend) pathSecretCreds() *framework.Secret {
 		Type:   secretCredsType,
 		Fields: map[string]*framework.FieldSchema{},
 
-		Renew:  b.secretCredsRenewHandler,
-		Revoke: b.secretCredsRevokeHandler,
+		Renew:  withSecretMetrics(secretCredsType, metricCredsRenew, b.secretCredsRenewHandler),
+		Revoke: withSecretMetrics(secretCredsType, metricCredsRevoke, b.secretCredsRevokeHandler),
 	}
 }
 
@@ -35,13 +37,18 @@ func (b *backend) secretCredsRenewHandler(ctx context.Context, req *logical.Requ
 	if role == nil {
 		return nil, fmt.Errorf("error during renew: could not find role with name %q", roleName)
 	}
-
-	nodeFQDN := ""
-	nodeFQDNRaw, ok := req.Secret.InternalData["node_fqdn"]
-	if ok {
-		nodeFQDN = nodeFQDNRaw.(string)
+	// the role might have been moved to another connection since the lease was issued
+	connNameRaw, ok := req.Secret.InternalData["connection"]
+	if !ok {
+		return nil, fmt.Errorf("no connection name was provided")
+	}
+	connName, ok := connNameRaw.(string)
+	if !ok {
+		return nil, fmt.Errorf("unable to convert connection name")
 	}
 
+	nodes := leaseNodes(req.Secret.InternalData)
+
 	// Make sure we increase the VALID UNTIL endpoint for this user.
 	ttl, _, err := framework.CalculateTTL(b.System(), req.Secret.Increment, role.DefaultTTL, 0, role.MaxTTL, 0, req.Secret.IssueTime)
 	if err != nil {
@@ -53,20 +60,39 @@ func (b *backend) secretCredsRenewHandler(ctx context.Context, req *logical.Requ
 	resp.Secret.MaxTTL = role.MaxTTL
 	if ttl > 0 {
 		expireTime := time.Now().Add(ttl)
-		_ = expireTime
-		config, err := connectionConfigLoad(ctx, req.Storage, role.Connection)
-		if err != nil {
-			return nil, err
+		if usernameRaw, ok := req.Secret.InternalData["username"]; ok {
+			// leases issued before the user index existed are added on renewal
+			index := &userIndexEntry{
+				Role:    roleName,
+				Expires: expireTime,
+			}
+			if len(nodes) > 1 {
+				index.NodeFQDNs = nodes
+			} else {
+				index.NodeFQDN = nodes[0]
+			}
+			if splunkRoleRaw, ok := req.Secret.InternalData["splunk_role"]; ok {
+				index.SplunkRole = splunkRoleRaw.(string)
+			}
+			if err := index.store(ctx, req.Storage, connName, usernameRaw.(string)); err != nil {
+				return nil, err
+			}
 		}
-		conn, err := b.ensureNodeConnection(ctx, config, nodeFQDN)
+		config, err := connectionConfigLoad(ctx, req.Storage, connName)
 		if err != nil {
 			return nil, err
 		}
-		if conn == nil {
-			return nil, fmt.Errorf("error getting Splunk connection")
-		}
-		if _, _, err = conn.Introspection.ServerInfo(); err != nil {
-			resp.AddWarning(fmt.Sprintf("failed to renew lease: %s", err))
+		for _, nodeFQDN := range nodes {
+			conn, err := b.ensureNodeConnection(ctx, config, nodeFQDN)
+			if err != nil {
+				return nil, err
+			}
+			if conn == nil {
+				return nil, fmt.Errorf("error getting Splunk connection")
+			}
+			if _, _, err = conn.Introspection.ServerInfo(ctx); err != nil {
+				resp.AddWarning(fmt.Sprintf("failed to renew lease: %s", err))
+			}
 		}
 	}
 	return resp, nil
@@ -81,41 +107,107 @@ func (b *backend) secretCredsRevokeHandler(ctx context.Context, req *logical.Req
 	if !ok {
 		return nil, fmt.Errorf("unable to convert connection name")
 	}
-	nodeFQDN := ""
-	nodeFQDNRaw, ok := req.Secret.InternalData["node_fqdn"]
-	if ok {
-		nodeFQDN = nodeFQDNRaw.(string)
-	}
+	nodes := leaseNodes(req.Secret.InternalData)
 	usernameRaw, ok := req.Secret.InternalData["username"]
 	if !ok {
 		return nil, fmt.Errorf("username is missing on the lease")
 	}
 	username := usernameRaw.(string)
+	splunkRole := ""
+	if splunkRoleRaw, ok := req.Secret.InternalData["splunk_role"]; ok {
+		splunkRole = splunkRoleRaw.(string)
+	}
 
 	config, err := connectionConfigLoad(ctx, req.Storage, connName)
 	if err != nil {
 		return nil, err
 	}
-	conn, err := b.ensureNodeConnection(ctx, config, nodeFQDN)
-	if err != nil {
+	if err := b.deleteUserOnNodes(ctx, config, nodes, username, splunkRole); err != nil {
 		return nil, err
 	}
-
-	_, _, err = conn.AccessControl.Authentication.Users.Delete(username)
-	if err != nil {
+	if err := userIndexDelete(ctx, req.Storage, connName, username); err != nil {
 		return nil, err
 	}
 	return nil, nil
 }
 
-func (b *backend) ensureNodeConnection(ctx context.Context, config *splunkConfig, nodeFQDN string) (*splunk.API, error) {
-	b.Logger().Debug("node connection", "nodeFQDN", nodeFQDN)
-	if nodeFQDN == "" {
-		return b.ensureConnection(ctx, config)
+// leaseNodes returns the nodes of a creds lease.  The empty string denotes the node of the connection URL.
+func leaseNodes(internalData map[string]interface{}) []string {
+	if nodesRaw, ok := internalData["node_fqdns"]; ok {
+		// []interface{} after the lease has been stored
+		var nodes []string
+		switch v := nodesRaw.(type) {
+		case []string:
+			nodes = v
+		case []interface{}:
+			for _, node := range v {
+				nodes = append(nodes, node.(string))
+			}
+		}
+		if len(nodes) > 0 {
+			return nodes
+		}
+	}
+	nodeFQDN := ""
+	if nodeFQDNRaw, ok := internalData["node_fqdn"]; ok {
+		nodeFQDN = nodeFQDNRaw.(string)
+	}
+	return []string{nodeFQDN}
+}
+
+// deleteUserOnNodes deletes the Splunk user, and its dynamic Splunk role if any, from all nodes in parallel.
+func (b *backend) deleteUserOnNodes(ctx context.Context, config *splunkConfig, nodes []string, username, splunkRole string) error {
+	errs := make([]error, len(nodes))
+	var wg sync.WaitGroup
+	for i, nodeFQDN := range nodes {
+		wg.Add(1)
+		go func(i int, nodeFQDN string) {
+			defer wg.Done()
+			conn, err := b.ensureNodeConnection(ctx, config, nodeFQDN)
+			if err == nil {
+				err = deleteSplunkUser(ctx, conn, username, splunkRole)
+			}
+			if err != nil && len(nodes) > 1 {
+				err = fmt.Errorf("node %q: %w", nodeFQDN, err)
+			}
+			errs[i] = err
+		}(i, nodeFQDN)
+	}
+	wg.Wait()
+	return joinErrors(errs)
+}
+
+// deleteSplunkUser deletes the Splunk user, and its dynamic Splunk role if not empty.  Users and roles
+// that do not exist anymore are ignored.
+func deleteSplunkUser(ctx context.Context, conn *splunk.API, username, splunkRole string) error {
+	if _, _, err := conn.AccessControl.Authentication.Users.Delete(ctx, username); err != nil && !splunk.IsNotFound(err) {
+		return fmt.Errorf("error deleting user %q: %w", username, err)
 	}
+	if splunkRole != "" {
+		if _, _, err := conn.AccessControl.Authorization.Roles.Delete(ctx, splunkRole); err != nil && !splunk.IsNotFound(err) {
+			return fmt.Errorf("error deleting role %q: %w", splunkRole, err)
+		}
+	}
+	return nil
+}
 
-	// we connect to a node, not the cluster master
-	nodeConfig := *config
-	nodeConfig.URL = "https://" + nodeFQDN + ":8089"
-	return nodeConfig.newConnection(ctx) // XXX cache
+// joinErrors combines the non-nil errors into one, or returns nil if there are none.
+func joinErrors(errs []error) error {
+	var msgs []string
+	for _, err := range errs {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	switch len(msgs) {
+	case 0:
+		return nil
+	case 1:
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "; "))
 }
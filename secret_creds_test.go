@@ -0,0 +1,15 @@
+package splunk
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_leaseNodes(t *testing.T) {
+	assert.DeepEqual(t, leaseNodes(map[string]interface{}{}), []string{""})
+	assert.DeepEqual(t, leaseNodes(map[string]interface{}{"node_fqdn": "idx1"}), []string{"idx1"})
+	assert.DeepEqual(t, leaseNodes(map[string]interface{}{"node_fqdns": []string{"idx1", "idx2"}}), []string{"idx1", "idx2"})
+	// as decoded from storage
+	assert.DeepEqual(t, leaseNodes(map[string]interface{}{"node_fqdns": []interface{}{"idx1", "idx2"}}), []string{"idx1", "idx2"})
+}
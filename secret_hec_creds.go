@@ -0,0 +1,78 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/splunk/vault-plugin-splunk/clients/splunk"
+)
+
+const secretHECCredsType = "hec-creds"
+
+func (b *backend) pathSecretHECCreds() *framework.Secret {
+	return &framework.Secret{
+		Type:   secretHECCredsType,
+		Fields: map[string]*framework.FieldSchema{},
+
+		Renew:  withSecretMetrics(secretHECCredsType, metricCredsRenew, b.secretHECCredsRenewHandler),
+		Revoke: withSecretMetrics(secretHECCredsType, metricCredsRevoke, b.secretHECCredsRevokeHandler),
+	}
+}
+
+func (b *backend) secretHECCredsRenewHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	roleNameRaw, ok := req.Secret.InternalData["role"]
+	if !ok {
+		return nil, fmt.Errorf("missing role name")
+	}
+	roleName := roleNameRaw.(string)
+	role, err := hecRoleConfigLoad(ctx, req.Storage, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, fmt.Errorf("error during renew: could not find HEC role with name %q", roleName)
+	}
+
+	// HEC tokens do not expire, hence only the lease is extended
+	resp := &logical.Response{Secret: req.Secret}
+	resp.Secret.TTL = role.DefaultTTL
+	resp.Secret.MaxTTL = role.MaxTTL
+	return resp, nil
+}
+
+func (b *backend) secretHECCredsRevokeHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	connNameRaw, ok := req.Secret.InternalData["connection"]
+	if !ok {
+		return nil, fmt.Errorf("no connection name was provided")
+	}
+	connName, ok := connNameRaw.(string)
+	if !ok {
+		return nil, fmt.Errorf("unable to convert connection name")
+	}
+	nameRaw, ok := req.Secret.InternalData["name"]
+	if !ok {
+		return nil, fmt.Errorf("HEC input name is missing on the lease")
+	}
+
+	config, err := connectionConfigLoad(ctx, req.Storage, connName)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := b.ensureConnection(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = conn.HEC.Delete(ctx, nameRaw.(string))
+	if err != nil {
+		if splunk.IsNotFound(err) {
+			// input is gone already
+			return nil, nil
+		}
+		return nil, err
+	}
+	return nil, nil
+}
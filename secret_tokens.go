@@ -0,0 +1,60 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/splunk/vault-plugin-splunk/clients/splunk"
+)
+
+const secretTokenType = "token"
+
+func (b *backend) pathSecretTokens() *framework.Secret {
+	return &framework.Secret{
+		Type:   secretTokenType,
+		Fields: map[string]*framework.FieldSchema{},
+
+		Revoke: withSecretMetrics(secretTokenType, metricCredsRevoke, b.secretTokensRevokeHandler),
+	}
+}
+
+func (b *backend) secretTokensRevokeHandler(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	connNameRaw, ok := req.Secret.InternalData["connection"]
+	if !ok {
+		return nil, fmt.Errorf("no connection name was provided")
+	}
+	connName, ok := connNameRaw.(string)
+	if !ok {
+		return nil, fmt.Errorf("unable to convert connection name")
+	}
+	usernameRaw, ok := req.Secret.InternalData["username"]
+	if !ok {
+		return nil, fmt.Errorf("username is missing on the lease")
+	}
+	tokenIDRaw, ok := req.Secret.InternalData["token_id"]
+	if !ok {
+		return nil, fmt.Errorf("token ID is missing on the lease")
+	}
+
+	config, err := connectionConfigLoad(ctx, req.Storage, connName)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := b.ensureConnection(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = conn.AccessControl.Authorization.Tokens.Delete(ctx, usernameRaw.(string), tokenIDRaw.(string))
+	if err != nil {
+		if splunk.IsNotFound(err) {
+			// token is gone already
+			return nil, nil
+		}
+		return nil, err
+	}
+	return nil, nil
+}
@@ -0,0 +1,97 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/splunk/vault-plugin-splunk/clients/splunk"
+)
+
+// shcReplicationPollInterval is the interval for checking whether a new user has been replicated to all
+// search head cluster members.  Tests shorten it.
+var shcReplicationPollInterval = time.Second
+
+// shcCaptain returns the current captain of the search head cluster the connection URL belongs to.
+func (b *backend) shcCaptain(ctx context.Context, config *splunkConfig) (*discoveredNode, error) {
+	conn, err := b.ensureConnection(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	info, _, err := conn.Deployment.SHCCaptainInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read search head cluster captain: %w", err)
+	}
+	if info == nil {
+		return nil, fmt.Errorf("unable to read search head cluster captain: empty response")
+	}
+	host := hostFromMgmtURI(info.Content.MgmtURI, info.Content.Label)
+	if host == "" {
+		return nil, fmt.Errorf("unable to read search head cluster captain: empty management URI")
+	}
+	return &discoveredNode{
+		Host:        host,
+		ServerName:  info.Content.Label,
+		GUID:        info.Name,
+		ServerRoles: []string{"search_head", "shc_captain"},
+	}, nil
+}
+
+// waitForSHCReplication waits until username exists on all available search head cluster members, or
+// until shc_replication_timeout has passed.  It returns the members the user has not been replicated to.
+//
+// Logging in as the user would be closer to what clients do, but failed attempts might lock the user out.
+func (b *backend) waitForSHCReplication(ctx context.Context, config *splunkConfig, username string) ([]string, error) {
+	conn, err := b.ensureConnection(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	members, err := collectNodes(conn.Deployment.SHCMembersPager(ctx, splunk.SHCMemberEntryFilterDefault), nodesFromSHCMembers)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read search head cluster members: %w", err)
+	}
+	pending := make(map[string]*discoveredNode)
+	for _, node := range members {
+		if node.Host != "" && node.available() {
+			node := node
+			pending[node.Host] = &node
+		}
+	}
+
+	deadline := time.Now().Add(config.SHCReplicationTimeout)
+	for {
+		for host, node := range pending {
+			nodeConn, err := b.ensureDiscoveredNodeConnection(ctx, config, node)
+			if err != nil {
+				return nil, err
+			}
+			users, _, err := nodeConn.AccessControl.Authentication.Users.Users(ctx, splunk.UserEntryFilterPrefix(username))
+			if err != nil {
+				b.Logger().Debug("error checking for replicated user", "node", host, "username", username, "err", err)
+				continue
+			}
+			for _, user := range users {
+				if user.Name == username {
+					delete(pending, host)
+					break
+				}
+			}
+		}
+		if len(pending) == 0 || !time.Now().Before(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(shcReplicationPollInterval):
+		}
+	}
+
+	result := make([]string, 0, len(pending))
+	for host := range pending {
+		result = append(result, host)
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_hostFromMgmtURI(t *testing.T) {
+	assert.Equal(t, hostFromMgmtURI("https://sh1.example.com:8089", "sh1"), "sh1.example.com")
+	assert.Equal(t, hostFromMgmtURI("https://10.0.0.1:8089", "sh1"), "10.0.0.1")
+	assert.Equal(t, hostFromMgmtURI("https://[::1]:8089", "sh1"), "::1")
+	assert.Equal(t, hostFromMgmtURI("", "sh1"), "sh1")
+	assert.Equal(t, hostFromMgmtURI("sh2.example.com:8089", "sh1"), "sh1")
+	assert.Equal(t, hostFromMgmtURI("", ""), "")
+}
+
+// testSHC returns a search head cluster of fake members, and a connection to the first member, which serves
+// the cluster endpoints.  The members
+// are reachable by their labels, which node_url_template uses; their hosts are "shN.example.com".
+func testSHC(t *testing.T, n int) (*backend, *splunkConfig, []*testFakeNode) {
+	members := make([]*testFakeNode, n)
+	var entries []map[string]interface{}
+	for i := range members {
+		members[i] = testNewFakeNode(t)
+		entries = append(entries, map[string]interface{}{
+			"name": "guid" + testFakeNodeName(i),
+			"content": map[string]interface{}{
+				"label":    members[i].hostPort(),
+				"mgmt_uri": fmt.Sprintf("https://sh%d.example.com:8089", i),
+				"status":   "Up",
+			},
+		})
+	}
+	members[0].setSHC(nil, entries)
+	config := &splunkConfig{
+		ID:              "id1",
+		URL:             members[0].URL,
+		Username:        "admin",
+		Password:        "adminpw",
+		NodeURLTemplate: "http://{{.ServerName}}",
+		SHCCaptain:      true,
+	}
+	return newBackend().(*backend), config, members
+}
+
+func TestBackend_shcCaptain(t *testing.T) {
+	b, config, members := testSHC(t, 2)
+	captainInfo := map[string]interface{}{
+		"name": "guidnode1",
+		"content": map[string]interface{}{
+			"label":    members[1].hostPort(),
+			"mgmt_uri": "https://sh1.example.com:8089",
+		},
+	}
+	members[0].setSHC(captainInfo, nil)
+
+	captain, err := b.shcCaptain(context.Background(), config)
+	assert.NilError(t, err)
+	assert.Equal(t, captain.Host, "sh1.example.com")
+	assert.Equal(t, captain.ServerName, members[1].hostPort())
+	assert.Equal(t, captain.GUID, "guidnode1")
+	assert.DeepEqual(t, captain.ServerRoles, []string{"search_head", "shc_captain"})
+
+	// credentials are managed on the captain
+	members[1].addUser("captain_user")
+	conn, err := b.ensureNodeConnection(context.Background(), config, "")
+	assert.NilError(t, err)
+	user, _, err := conn.AccessControl.Authentication.Users.User(context.Background(), "captain_user")
+	assert.NilError(t, err)
+	assert.Equal(t, user.Name, "captain_user")
+
+	members[0].setSHC(map[string]interface{}{
+		"name":    "guidnode1",
+		"content": map[string]interface{}{"mgmt_uri": ""},
+	}, nil)
+	_, err = b.shcCaptain(context.Background(), config)
+	assert.ErrorContains(t, err, "empty management URI")
+
+	members[0].setSHC(nil, nil)
+	_, err = b.shcCaptain(context.Background(), config)
+	assert.ErrorContains(t, err, "unable to read search head cluster captain")
+}
+
+func TestBackend_waitForSHCReplication(t *testing.T) {
+	defer func(interval time.Duration) { shcReplicationPollInterval = interval }(shcReplicationPollInterval)
+	shcReplicationPollInterval = time.Millisecond
+
+	b, config, members := testSHC(t, 3)
+	config.SHCReplicationTimeout = time.Minute
+	members[0].addUser("test_user")
+	members[1].addUser("test_user")
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		members[2].addUser("test_user")
+	}()
+
+	// polls until the user is on all members
+	pending, err := b.waitForSHCReplication(context.Background(), config, "test_user")
+	assert.NilError(t, err)
+	assert.Equal(t, len(pending), 0)
+	assert.Assert(t, members[2].hasUser("test_user"))
+
+	// members the user is not replicated to in time are reported
+	config.SHCReplicationTimeout = 20 * time.Millisecond
+	members[1].addUser("test_other")
+	start := time.Now()
+	pending, err = b.waitForSHCReplication(context.Background(), config, "test_other")
+	assert.NilError(t, err)
+	assert.Assert(t, time.Since(start) >= config.SHCReplicationTimeout)
+	assert.DeepEqual(t, pending, []string{"sh0.example.com", "sh2.example.com"})
+}
+
+func TestBackend_waitForSHCReplicationCanceled(t *testing.T) {
+	defer func(interval time.Duration) { shcReplicationPollInterval = interval }(shcReplicationPollInterval)
+	shcReplicationPollInterval = time.Millisecond
+
+	b, config, _ := testSHC(t, 2)
+	config.SHCReplicationTimeout = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := b.waitForSHCReplication(ctx, config, "test_user")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
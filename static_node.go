@@ -0,0 +1,74 @@
+package splunk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// staticNode is a node of a multi-node deployment that is configured explicitly, for clusters without
+// a cluster manager.
+type staticNode struct {
+	FQDN        string   `json:"fqdn" mapstructure:"fqdn"`
+	URL         string   `json:"url,omitempty" mapstructure:"url"`
+	ServerRoles []string `json:"server_roles" mapstructure:"server_roles"`
+	Site        string   `json:"site,omitempty" mapstructure:"site"`
+
+	// optional admin credentials for this node; the connection's credentials are used otherwise
+	Username string `json:"username,omitempty" mapstructure:"username"`
+	Password string `json:"password,omitempty" mapstructure:"password"`
+}
+
+// parseStaticNodes decodes and validates the "nodes" parameter of a connection.
+func parseStaticNodes(raw []interface{}) ([]staticNode, error) {
+	if len(raw) == 1 {
+		// e.g., "nodes=@nodes.json" on the command line
+		if s, ok := raw[0].(string); ok {
+			raw = nil
+			if err := json.Unmarshal([]byte(s), &raw); err != nil {
+				return nil, fmt.Errorf("invalid nodes: %w", err)
+			}
+		}
+	}
+	var nodes []staticNode
+	if err := mapstructure.WeakDecode(raw, &nodes); err != nil {
+		return nil, fmt.Errorf("invalid nodes: %w", err)
+	}
+	seen := make(map[string]bool)
+	for i, node := range nodes {
+		if node.FQDN == "" {
+			return nil, fmt.Errorf("invalid nodes: missing fqdn for node %d", i)
+		}
+		fqdn := strings.ToLower(node.FQDN)
+		if seen[fqdn] {
+			return nil, fmt.Errorf("invalid nodes: duplicate node %q", node.FQDN)
+		}
+		seen[fqdn] = true
+		if node.URL != "" {
+			if u, err := url.Parse(node.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				return nil, fmt.Errorf("invalid nodes: invalid url %q for node %q", node.URL, node.FQDN)
+			}
+		}
+		if (node.Username == "") != (node.Password == "") {
+			return nil, fmt.Errorf("invalid nodes: username and password must be set together for node %q", node.FQDN)
+		}
+	}
+	return nodes, nil
+}
+
+func (node *staticNode) toResponseData() map[string]interface{} {
+	data := map[string]interface{}{
+		"fqdn":         node.FQDN,
+		"url":          node.URL,
+		"server_roles": node.ServerRoles,
+		"site":         node.Site,
+	}
+	if node.Username != "" {
+		data["username"] = node.Username
+		data["password"] = "n/a"
+	}
+	return data
+}
@@ -0,0 +1,69 @@
+package splunk
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_parseStaticNodes(t *testing.T) {
+	nodes, err := parseStaticNodes([]interface{}{
+		map[string]interface{}{"fqdn": "idx1.example.com", "server_roles": []interface{}{"indexer"}},
+		map[string]interface{}{"fqdn": "idx2.example.com", "server_roles": "indexer", "url": "https://10.0.0.2:18089",
+			"username": "admin", "password": "secret"},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, nodes, []staticNode{
+		{FQDN: "idx1.example.com", ServerRoles: []string{"indexer"}},
+		{FQDN: "idx2.example.com", ServerRoles: []string{"indexer"}, URL: "https://10.0.0.2:18089", Username: "admin", Password: "secret"},
+	})
+
+	nodes, err = parseStaticNodes([]interface{}{`[{"fqdn": "idx1.example.com", "server_roles": ["indexer"]}]`})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, nodes, []staticNode{{FQDN: "idx1.example.com", ServerRoles: []string{"indexer"}}})
+
+	_, err = parseStaticNodes([]interface{}{map[string]interface{}{"url": "https://10.0.0.1:8089"}})
+	assert.ErrorContains(t, err, "missing fqdn")
+	_, err = parseStaticNodes([]interface{}{
+		map[string]interface{}{"fqdn": "idx1"},
+		map[string]interface{}{"fqdn": "IDX1"},
+	})
+	assert.ErrorContains(t, err, "duplicate node")
+	_, err = parseStaticNodes([]interface{}{map[string]interface{}{"fqdn": "idx1", "url": "10.0.0.1:8089"}})
+	assert.ErrorContains(t, err, "invalid url")
+	_, err = parseStaticNodes([]interface{}{map[string]interface{}{"fqdn": "idx1", "username": "admin"}})
+	assert.ErrorContains(t, err, "username and password")
+}
+
+func TestBackend_staticNodeConnection(t *testing.T) {
+	b := newBackend().(*backend)
+	ctx := context.Background()
+	config := &splunkConfig{
+		ID:       "id1",
+		URL:      "https://sh1.example.com:8089",
+		Username: "admin",
+		Password: "adminpw",
+		Nodes: []staticNode{
+			{FQDN: "idx1.example.com", ServerRoles: []string{"indexer"}},
+			{FQDN: "idx2.example.com", ServerRoles: []string{"indexer"}, URL: "https://10.0.0.2:18089", Username: "idxadmin", Password: "idxpw"},
+		},
+	}
+
+	nodes, err := b.discoverNodes(ctx, config)
+	assert.NilError(t, err)
+	node, err := findNode("IDX2.example.com", nodes, &roleConfig{AllowedServerRoles: []string{"indexer"}})
+	assert.NilError(t, err)
+	assert.Equal(t, node.Host, "idx2.example.com")
+
+	conn, err := b.ensureNodeConnection(ctx, config, "idx1.example.com")
+	assert.NilError(t, err)
+	assert.Equal(t, conn.Params().BaseURL, "https://idx1.example.com:8089")
+	assert.Equal(t, conn.Params().Config.ClientID, "admin")
+
+	conn, err = b.ensureNodeConnection(ctx, config, "idx2.example.com")
+	assert.NilError(t, err)
+	assert.Equal(t, conn.Params().BaseURL, "https://10.0.0.2:18089")
+	assert.Equal(t, conn.Params().Config.ClientID, "idxadmin")
+	assert.Equal(t, conn.Params().Config.ClientSecret, "idxpw")
+}
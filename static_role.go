@@ -0,0 +1,134 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fatih/structs"
+	"github.com/hashicorp/vault/sdk/logical"
+
+	"github.com/splunk/vault-plugin-splunk/clients/splunk"
+)
+
+const (
+	staticRolesPrefix = "static-roles/"
+
+	minRotationPeriod = time.Minute
+)
+
+type staticRoleConfig struct {
+	Connection     string        `json:"connection" structs:"connection"`
+	Username       string        `json:"username" structs:"username"`
+	RotationPeriod time.Duration `json:"rotation_period" structs:"rotation_period"`
+	PasswordSpec   *PasswordSpec `json:"password_spec" structs:"password_spec"`
+
+	// managed by the plugin
+	Password    string    `json:"password" structs:"-"`
+	LastRotated time.Time `json:"last_vault_rotation" structs:"last_vault_rotation,omitnested"`
+}
+
+// staticRoleConfigLoad returns nil if the static role named `name` does not exist in `storage`, otherwise
+// returns the static role.  The second return value is non-nil on error.
+func staticRoleConfigLoad(ctx context.Context, s logical.Storage, name string) (*staticRoleConfig, error) {
+	if name == "" {
+		return nil, fmt.Errorf("invalid static role name")
+	}
+
+	entry, err := s.Get(ctx, staticRolesPrefix+name)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving static role: %w", err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	role := staticRoleConfig{}
+	if err := entry.DecodeJSON(&role); err != nil {
+		return nil, fmt.Errorf("error decoding static role: %w", err)
+	}
+	return &role, nil
+}
+
+func (role *staticRoleConfig) store(ctx context.Context, s logical.Storage, name string) error {
+	entry, err := logical.StorageEntryJSON(staticRolesPrefix+name, role)
+	if err != nil {
+		return err
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return fmt.Errorf("error writing %q JSON: %w", staticRolesPrefix+name, err)
+	}
+	return nil
+}
+
+// staticRoleSecrets are the passwords of static roles.
+var staticRoleSecrets = &rotatedSecretKind{
+	name:    "static role",
+	prefix:  staticRolesPrefix,
+	walType: walTypeStaticRole,
+	lock:    func(b *backend) *sync.Mutex { return &b.staticRoleLock },
+	load: func(ctx context.Context, s logical.Storage, name string) (rotatedSecret, error) {
+		role, err := staticRoleConfigLoad(ctx, s, name)
+		if role == nil {
+			return nil, err
+		}
+		return role, err
+	},
+	new: func() rotatedSecret { return &staticRoleConfig{} },
+}
+
+// NextRotation returns the time of the next scheduled password rotation.
+func (role *staticRoleConfig) NextRotation() time.Time {
+	return role.LastRotated.Add(role.RotationPeriod)
+}
+
+func (role *staticRoleConfig) connectionName() string {
+	return role.Connection
+}
+
+func (role *staticRoleConfig) passwordSpec() *PasswordSpec {
+	return role.PasswordSpec
+}
+
+func (role *staticRoleConfig) current() string {
+	return role.Password
+}
+
+func (role *staticRoleConfig) lastRotated() time.Time {
+	return role.LastRotated
+}
+
+func (role *staticRoleConfig) rotated(password string, now time.Time) {
+	role.Password = password
+	role.LastRotated = now
+}
+
+func (role *staticRoleConfig) sameTarget(other rotatedSecret) bool {
+	otherRole, ok := other.(*staticRoleConfig)
+	return ok && role.Connection == otherRole.Connection && role.Username == otherRole.Username
+}
+
+// apply sets password for the user of the role.
+func (role *staticRoleConfig) apply(ctx context.Context, b *backend, config *splunkConfig, password string) error {
+	conn, err := b.ensureConnection(ctx, config)
+	if err != nil {
+		return err
+	}
+	opts := splunk.UpdateUserOptions{
+		Password: password,
+	}
+	if _, _, err := conn.AccessControl.Authentication.Users.Update(ctx, role.Username, &opts); err != nil {
+		return fmt.Errorf("error updating password for user %q: %w", role.Username, err)
+	}
+	return nil
+}
+
+func (role *staticRoleConfig) toResponseData() map[string]interface{} {
+	data := structs.New(role).Map()
+	data["rotation_period"] = int64(role.RotationPeriod.Seconds())
+	data["next_vault_rotation"] = role.NextRotation()
+	delete(data, "password_spec")
+	addPasswordSpecResponseData(data, role.PasswordSpec)
+	return data
+}
@@ -0,0 +1,391 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/splunk/vault-plugin-splunk/clients/splunk"
+)
+
+const (
+	tidyStatePrefix = "tidy-state/"
+
+	defaultTidySafetyBuffer = 72 * time.Hour
+)
+
+// tidyState tracks tidy runs for a connection.
+type tidyState struct {
+	LastTidy time.Time `json:"last_tidy"`
+
+	// users without lease that were found by earlier runs, by username
+	Candidates map[string]tidyCandidate `json:"candidates"`
+}
+
+type tidyCandidate struct {
+	NodeFQDN  string    `json:"node_fqdn,omitempty"`
+	FirstSeen time.Time `json:"first_seen"`
+}
+
+func tidyStateLoad(ctx context.Context, s logical.Storage, name string) (*tidyState, error) {
+	entry, err := s.Get(ctx, tidyStatePrefix+name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tidy state: %w", err)
+	}
+	state := tidyState{}
+	if entry != nil {
+		if err := entry.DecodeJSON(&state); err != nil {
+			return nil, fmt.Errorf("error decoding tidy state: %w", err)
+		}
+	}
+	if state.Candidates == nil {
+		state.Candidates = make(map[string]tidyCandidate)
+	}
+	return &state, nil
+}
+
+func (state *tidyState) store(ctx context.Context, s logical.Storage, name string) error {
+	entry, err := logical.StorageEntryJSON(tidyStatePrefix+name, state)
+	if err != nil {
+		return err
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return fmt.Errorf("error writing %q JSON: %w", tidyStatePrefix+name, err)
+	}
+	return nil
+}
+
+type tidyOptions struct {
+	// user name prefixes to consider, without the trailing "_"
+	UserPrefixes []string
+	// node to tidy for multi-node connections; all search peers if empty
+	NodeFQDN     string
+	SafetyBuffer time.Duration
+	DryRun       bool
+
+	// time that a user unknown to the user index must have been seen before it is deleted
+	unknownBuffer time.Duration
+}
+
+// tidyOrphan is a user found by tidy that is to be deleted.
+type tidyOrphan struct {
+	username string
+	index    *userIndexEntry // nil if the user is unknown to Vault
+	// connection that index is stored under, which might be another one pointing to the same deployment
+	indexConn string
+}
+
+type tidyResult struct {
+	Deleted []string
+	Pending []string
+	Errors  []string
+}
+
+// tidyUsers deletes Splunk users created by Vault for which no lease exists anymore.
+//
+// A user is deleted if its lease expired more than SafetyBuffer ago, or if it is not in the
+// user index at all and was first seen by tidy more than SafetyBuffer ago.  The latter covers
+// users from failed revocations, restored snapshots and deleted connections.  Leases issued
+// before the user index existed are only indexed on renewal, so unknown users are also kept
+// until no lease of the connection's roles can still be valid, i.e., for the max TTL.
+//
+// A dry run neither records new candidates nor stores the tidy state.
+//
+// The caller must hold tidyLock.
+func (b *backend) tidyUsers(ctx context.Context, s logical.Storage, name string, config *splunkConfig, opts *tidyOptions) (*tidyResult, error) {
+	state, err := tidyStateLoad(ctx, s, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.UserPrefixes) == 0 {
+		if opts.UserPrefixes, err = connectionUserPrefixes(ctx, s, name); err != nil {
+			return nil, err
+		}
+	}
+	excluded, err := connectionStaticUsers(ctx, s, name)
+	if err != nil {
+		return nil, err
+	}
+	excluded[config.Username] = true
+	maxTTL, err := b.connectionMaxTTL(ctx, s, name)
+	if err != nil {
+		return nil, err
+	}
+	opts.unknownBuffer = opts.SafetyBuffer
+	if maxTTL > opts.unknownBuffer {
+		opts.unknownBuffer = maxTTL
+	}
+
+	nodes := []string{opts.NodeFQDN}
+	if !config.IsStandalone && opts.NodeFQDN == "" {
+		discovered, err := b.discoverNodes(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		nodes = nodes[:0]
+		for _, node := range discovered {
+			if node.Host != "" && node.available() {
+				nodes = append(nodes, node.Host)
+			}
+		}
+	}
+
+	now := time.Now()
+	result := &tidyResult{}
+	for _, nodeFQDN := range nodes {
+		if err := b.tidyNode(ctx, s, name, config, nodeFQDN, opts, state, excluded, now, result); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+
+	if !opts.DryRun {
+		state.LastTidy = now
+		if err := state.store(ctx, s, name); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(result.Deleted)
+	sort.Strings(result.Pending)
+	return result, nil
+}
+
+func (b *backend) tidyNode(ctx context.Context, s logical.Storage, name string, config *splunkConfig, nodeFQDN string,
+	opts *tidyOptions, state *tidyState, excluded map[string]bool, now time.Time, result *tidyResult) error {
+	conn, err := b.ensureNodeConnection(ctx, config, nodeFQDN)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	// deleting users while paging through them would shift the offsets of later pages
+	var orphans []tidyOrphan
+	for _, prefix := range opts.UserPrefixes {
+		prefix += "_"
+		pager := conn.AccessControl.Authentication.Users.UsersPager(ctx, splunk.UserEntryFilterPrefix(prefix))
+		for pager.Next() {
+			for _, user := range pager.Page() {
+				// the server-side search is not anchored
+				if !strings.HasPrefix(user.Name, prefix) || excluded[user.Name] || seen[user.Name] {
+					continue
+				}
+				seen[user.Name] = true
+
+				index, indexConn, err := userIndexFind(ctx, s, name, user.Name)
+				if err != nil {
+					return err
+				}
+				if index != nil {
+					if now.Before(index.Expires.Add(opts.SafetyBuffer)) {
+						continue
+					}
+				} else {
+					candidate, ok := state.Candidates[user.Name]
+					if !ok {
+						candidate = tidyCandidate{NodeFQDN: nodeFQDN, FirstSeen: now}
+						if !opts.DryRun {
+							state.Candidates[user.Name] = candidate
+						}
+					}
+					if now.Before(candidate.FirstSeen.Add(opts.unknownBuffer)) {
+						result.Pending = append(result.Pending, user.Name)
+						continue
+					}
+				}
+				orphans = append(orphans, tidyOrphan{username: user.Name, index: index, indexConn: indexConn})
+			}
+		}
+		if err := pager.Err(); err != nil {
+			return fmt.Errorf("error listing users with prefix %q: %w", prefix, err)
+		}
+	}
+
+	for _, orphan := range orphans {
+		if opts.DryRun {
+			result.Deleted = append(result.Deleted, orphan.username)
+			continue
+		}
+		b.Logger().Info("deleting orphaned user", "connection", name, "node", nodeFQDN, "username", orphan.username)
+		if _, _, err := conn.AccessControl.Authentication.Users.Delete(ctx, orphan.username); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("error deleting user %q: %s", orphan.username, err))
+			continue
+		}
+		if orphan.index != nil && orphan.index.SplunkRole != "" {
+			if _, _, err := conn.AccessControl.Authorization.Roles.Delete(ctx, orphan.index.SplunkRole); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("error deleting role %q: %s", orphan.index.SplunkRole, err))
+			}
+		}
+		if err := tidyIndexDelete(ctx, s, orphan.indexConn, orphan.username, orphan.index, nodeFQDN); err != nil {
+			return err
+		}
+		delete(state.Candidates, orphan.username)
+		result.Deleted = append(result.Deleted, orphan.username)
+	}
+
+	// forget about candidates that disappeared in the meantime
+	for username, candidate := range state.Candidates {
+		if candidate.NodeFQDN == nodeFQDN && !seen[username] && hasAnyPrefix(username, opts.UserPrefixes) {
+			delete(state.Candidates, username)
+		}
+	}
+	if opts.DryRun {
+		return nil
+	}
+
+	// drop index entries of users that are gone, e.g., deleted manually
+	usernames, err := s.List(ctx, userIndexPrefix+name+"/")
+	if err != nil {
+		return fmt.Errorf("error listing user index: %w", err)
+	}
+	for _, username := range usernames {
+		if seen[username] || !hasAnyPrefix(username, opts.UserPrefixes) {
+			continue
+		}
+		index, err := userIndexLoad(ctx, s, name, username)
+		if err != nil {
+			return err
+		}
+		if index != nil && index.onNode(nodeFQDN) && now.After(index.Expires.Add(opts.SafetyBuffer)) {
+			if err := tidyIndexDelete(ctx, s, name, username, index, nodeFQDN); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tidyIndexDelete removes node nodeFQDN from the index entry of a user stored under connection indexConn, which
+// is deleted once no nodes are left.  Users unknown to the index have no entry to remove.
+func tidyIndexDelete(ctx context.Context, s logical.Storage, indexConn, username string, index *userIndexEntry, nodeFQDN string) error {
+	if index == nil {
+		return nil
+	}
+	if len(index.NodeFQDNs) > 1 {
+		index.NodeFQDNs = strutil.StrListDelete(index.NodeFQDNs, nodeFQDN)
+		return index.store(ctx, s, indexConn, username)
+	}
+	return userIndexDelete(ctx, s, indexConn, username)
+}
+
+// connectionUserPrefixes returns the user name prefixes of all roles using connection name.
+func connectionUserPrefixes(ctx context.Context, s logical.Storage, name string) ([]string, error) {
+	roles, err := s.List(ctx, rolesPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("error listing roles: %w", err)
+	}
+	prefixes := map[string]bool{defaultUserPrefix: true}
+	for _, roleName := range roles {
+		role, err := roleConfigLoad(ctx, s, roleName)
+		if err != nil {
+			return nil, err
+		}
+		if role != nil && role.Connection == name {
+			prefixes[role.UserPrefix] = true
+		}
+	}
+	result := make([]string, 0, len(prefixes))
+	for prefix := range prefixes {
+		result = append(result, prefix)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// connectionMaxTTL returns the longest max TTL that a lease of a role using connection name can have.
+func (b *backend) connectionMaxTTL(ctx context.Context, s logical.Storage, name string) (time.Duration, error) {
+	roles, err := s.List(ctx, rolesPrefix)
+	if err != nil {
+		return 0, fmt.Errorf("error listing roles: %w", err)
+	}
+	maxTTL := b.System().MaxLeaseTTL()
+	for _, roleName := range roles {
+		role, err := roleConfigLoad(ctx, s, roleName)
+		if err != nil {
+			return 0, err
+		}
+		if role != nil && role.Connection == name && role.MaxTTL > maxTTL {
+			maxTTL = role.MaxTTL
+		}
+	}
+	return maxTTL, nil
+}
+
+// connectionStaticUsers returns the users managed by static roles using connection name.
+func connectionStaticUsers(ctx context.Context, s logical.Storage, name string) (map[string]bool, error) {
+	roles, err := s.List(ctx, staticRolesPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("error listing static roles: %w", err)
+	}
+	users := make(map[string]bool)
+	for _, roleName := range roles {
+		role, err := staticRoleConfigLoad(ctx, s, roleName)
+		if err != nil {
+			return nil, err
+		}
+		if role != nil && role.Connection == name {
+			users[role.Username] = true
+		}
+	}
+	return users, nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+// tidyConnections runs tidy on all connections with a tidy period that are due.
+func (b *backend) tidyConnections(ctx context.Context, s logical.Storage) error {
+	b.tidyLock.Lock()
+	defer b.tidyLock.Unlock()
+
+	names, err := s.List(ctx, "config/")
+	if err != nil {
+		return fmt.Errorf("error listing connections: %w", err)
+	}
+
+	var failed []string
+	for _, name := range names {
+		if strings.HasSuffix(name, "/") {
+			continue
+		}
+		config, err := connectionConfigLoad(ctx, s, name)
+		if err != nil {
+			b.Logger().Error("error loading connection for tidy", "connection", name, "err", err)
+			continue
+		}
+		if config.TidyPeriod == 0 {
+			continue
+		}
+		state, err := tidyStateLoad(ctx, s, name)
+		if err != nil {
+			b.Logger().Error("error loading tidy state", "connection", name, "err", err)
+			continue
+		}
+		if time.Now().Before(state.LastTidy.Add(config.TidyPeriod)) {
+			continue
+		}
+
+		result, err := b.tidyUsers(ctx, s, name, config, &tidyOptions{
+			SafetyBuffer: config.tidySafetyBuffer(),
+		})
+		if err == nil && len(result.Errors) > 0 {
+			err = fmt.Errorf("%s", strings.Join(result.Errors, "; "))
+		}
+		if err != nil {
+			b.Logger().Error("error tidying users", "connection", name, "err", err)
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("error tidying users for connections: %q", failed)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package splunk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestBackend_tidyUsersIndexedUnderOtherConnection(t *testing.T) {
+	ctx := context.Background()
+	node := testNewFakeNode(t)
+	node.addUser("vault_single")
+	node.addUser("vault_multi")
+	b, storage := testFakeNodesBackend(t, node)
+	config, err := connectionConfigLoad(ctx, storage, "testconn")
+	assert.NilError(t, err)
+
+	// both users were issued via another connection to the same deployment, and expired long ago
+	expired := time.Now().Add(-2 * time.Hour)
+	single := &userIndexEntry{Role: "role", NodeFQDN: "node0", Expires: expired}
+	assert.NilError(t, single.store(ctx, storage, "otherconn", "vault_single"))
+	multi := &userIndexEntry{Role: "role", NodeFQDNs: []string{"node0", "node1"}, Expires: expired}
+	assert.NilError(t, multi.store(ctx, storage, "otherconn", "vault_multi"))
+
+	result, err := b.tidyUsers(ctx, storage, "testconn", config, &tidyOptions{
+		NodeFQDN:     "node0",
+		SafetyBuffer: time.Hour,
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, result.Errors, []string(nil))
+	assert.DeepEqual(t, result.Deleted, []string{"vault_multi", "vault_single"})
+	assert.Assert(t, !node.hasUser("vault_single"))
+	assert.Assert(t, !node.hasUser("vault_multi"))
+
+	index, err := userIndexLoad(ctx, storage, "otherconn", "vault_single")
+	assert.NilError(t, err)
+	assert.Assert(t, index == nil)
+	index, err = userIndexLoad(ctx, storage, "otherconn", "vault_multi")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, index.NodeFQDNs, []string{"node1"})
+	usernames, err := storage.List(ctx, userIndexPrefix+"testconn/")
+	assert.NilError(t, err)
+	assert.Equal(t, len(usernames), 0)
+}
@@ -0,0 +1,94 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const userIndexPrefix = "users/"
+
+// userIndexEntry records a Splunk user with an active lease.  The index allows tidy to tell
+// users created by Vault which are still in use from orphaned ones.
+//
+// NodeFQDNs is set instead of NodeFQDN for users created on several nodes.
+type userIndexEntry struct {
+	Role       string    `json:"role"`
+	NodeFQDN   string    `json:"node_fqdn,omitempty"`
+	NodeFQDNs  []string  `json:"node_fqdns,omitempty"`
+	SplunkRole string    `json:"splunk_role,omitempty"`
+	Expires    time.Time `json:"expires"`
+}
+
+// onNode returns true if the user was created on node nodeFQDN.
+func (index *userIndexEntry) onNode(nodeFQDN string) bool {
+	if len(index.NodeFQDNs) > 0 {
+		return strutil.StrListContains(index.NodeFQDNs, nodeFQDN)
+	}
+	return index.NodeFQDN == nodeFQDN
+}
+
+func userIndexKey(connName, username string) string {
+	return fmt.Sprintf("%s%s/%s", userIndexPrefix, connName, username)
+}
+
+func userIndexLoad(ctx context.Context, s logical.Storage, connName, username string) (*userIndexEntry, error) {
+	entry, err := s.Get(ctx, userIndexKey(connName, username))
+	if err != nil {
+		return nil, fmt.Errorf("error reading user index: %w", err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+	index := userIndexEntry{}
+	if err := entry.DecodeJSON(&index); err != nil {
+		return nil, fmt.Errorf("error decoding user index: %w", err)
+	}
+	return &index, nil
+}
+
+func (index *userIndexEntry) store(ctx context.Context, s logical.Storage, connName, username string) error {
+	entry, err := logical.StorageEntryJSON(userIndexKey(connName, username), index)
+	if err != nil {
+		return err
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return fmt.Errorf("error writing user index for %q: %w", username, err)
+	}
+	return nil
+}
+
+func userIndexDelete(ctx context.Context, s logical.Storage, connName, username string) error {
+	if err := s.Delete(ctx, userIndexKey(connName, username)); err != nil {
+		return fmt.Errorf("error deleting user index for %q: %w", username, err)
+	}
+	return nil
+}
+
+// userIndexFind looks up username in the index of all connections, and returns the entry and the connection
+// it is stored under.  Several connections might point to the same Splunk deployment, so a user unknown to one
+// of them is not necessarily orphaned.
+func userIndexFind(ctx context.Context, s logical.Storage, connName, username string) (*userIndexEntry, string, error) {
+	index, err := userIndexLoad(ctx, s, connName, username)
+	if err != nil || index != nil {
+		return index, connName, err
+	}
+	conns, err := s.List(ctx, userIndexPrefix)
+	if err != nil {
+		return nil, "", fmt.Errorf("error listing user index: %w", err)
+	}
+	for _, conn := range conns {
+		conn = strings.TrimSuffix(conn, "/")
+		if conn == connName {
+			continue
+		}
+		if index, err := userIndexLoad(ctx, s, conn, username); err != nil || index != nil {
+			return index, conn, err
+		}
+	}
+	return nil, "", nil
+}